@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/uzh13/GuePetri/pkg/petri/analysis"
+	"github.com/uzh13/GuePetri/pkg/petri/definition"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/render"
+	"github.com/uzh13/GuePetri/pkg/petri/repl"
+)
+
+var (
+	errInvalid  = errors.New("invalid definitions")
+	errProblems = errors.New("problems found")
+)
+
+// cmdValidate builds every definition and reports those failing.
+func cmdValidate(args []string, stdout, stderr io.Writer) error {
+	fs := flags("validate", stderr)
+
+	err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, path := range fs.Args() {
+		_, _, err := load(path)
+		if err != nil {
+			failed++
+			fmt.Fprintf(stdout, "%s: %v\n", path, err)
+
+			continue
+		}
+
+		fmt.Fprintf(stdout, "%s: ok\n", path)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d: %w", failed, fs.NArg(), errInvalid)
+	}
+
+	return nil
+}
+
+// cmdAnalyze reports reachability, deadlocks and boundedness of every
+// definition.
+func cmdAnalyze(args []string, stdout, stderr io.Writer) error {
+	fs := flags("analyze", stderr)
+	limit := fs.Int("limit", analysis.DefaultLimit, "maximum number of markings explored for boundedness")
+
+	err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i, path := range fs.Args() {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+
+		fmt.Fprintf(stdout, "%s:\n", path)
+
+		d, _, err := load(path)
+		if err != nil {
+			failed++
+			fmt.Fprintf(stdout, "invalid: %v\n", err)
+
+			continue
+		}
+
+		r := analysis.AnalyzeLimit(d.Net(), *limit)
+		if !r.OK() {
+			failed++
+		}
+
+		err = r.Write(stdout)
+		if err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d: %w", failed, fs.NArg(), errProblems)
+	}
+
+	return nil
+}
+
+// cmdRender writes the diagram of a definition. SVG is made from DOT text by
+// the dot command of Graphviz.
+func cmdRender(args []string, stdout, stderr io.Writer) error {
+	fs := flags("render", stderr)
+	format := fs.String("format", "dot", "diagram format: dot, mermaid, plantuml or svg")
+	current := fs.Bool("current", false, "highlight the current place")
+	out := fs.String("o", "", "output file instead of standard output")
+
+	err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+
+	_, g, err := load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var opts []render.Option
+	if *current {
+		opts = append(opts, render.WithCurrent())
+	}
+
+	b := bytes.Buffer{}
+	switch *format {
+	case "dot":
+		err = render.DOT(&b, g, opts...)
+	case "mermaid":
+		err = render.Mermaid(&b, g, opts...)
+	case "plantuml":
+		err = render.PlantUML(&b, g, opts...)
+	case "svg":
+		err = svg(&b, g, opts)
+	default:
+		return fmt.Errorf("unknown format %q: %w", *format, errUsage)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = b.WriteTo(stdout)
+
+		return err
+	}
+
+	return os.WriteFile(*out, b.Bytes(), 0o644)
+}
+
+func svg(w io.Writer, g *graph.Petri[string, string], opts []render.Option) error {
+	dot, err := exec.LookPath("dot")
+	if err != nil {
+		return fmt.Errorf("svg needs Graphviz: %w", err)
+	}
+
+	in := bytes.Buffer{}
+
+	err = render.DOT(&in, g, opts...)
+	if err != nil {
+		return err
+	}
+
+	stderr := bytes.Buffer{}
+
+	cmd := exec.Command(dot, "-Tsvg")
+	cmd.Stdin = &in
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("running dot: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	return nil
+}
+
+// cmdSimulate sends the signals to the graph of a definition and prints every
+// step. Ignored signals leave the graph where it is.
+func cmdSimulate(args []string, stdout, stderr io.Writer) error {
+	fs := flags("simulate", stderr)
+	expect := fs.String("expect", "", "fail unless the graph ends on the place")
+
+	err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	_, g, err := load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if g.Current == nil {
+		err = g.StartGraph()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "start    %s\n", g.Current.ID)
+	} else {
+		fmt.Fprintf(stdout, "resume   %s\n", g.Current.ID)
+	}
+
+	signals := fs.Args()[1:]
+	for i, signal := range signals {
+		if g.IsOnFinish() {
+			return fmt.Errorf("graph finished with %d signals left: %v", len(signals)-i, signals[i:])
+		}
+
+		fired := len(g.Fired)
+
+		err = g.Act(signal)
+		switch {
+		case errors.Is(err, graph.ErrSignalIgnored):
+			fmt.Fprintf(stdout, "ignore   %s at %s\n", signal, g.Current.ID)
+		case err != nil:
+			return fmt.Errorf("signal %s: %w", signal, err)
+		default:
+			for _, f := range g.Fired[fired:] {
+				fmt.Fprintf(stdout, "fire     %s: %s -> %s -> %s\n", f.Signal, f.From, f.Transition, f.To)
+			}
+		}
+	}
+
+	if g.IsOnFinish() {
+		err = g.FinishGraph()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "finish   %s\n", g.Current.ID)
+	}
+
+	if *expect != "" && g.Current.ID != *expect {
+		return fmt.Errorf("graph ended on %s, expected %s", g.Current.ID, *expect)
+	}
+
+	return nil
+}
+
+// cmdREPL steps through the queue of a storage interactively.
+func cmdREPL(args []string, stdout, stderr io.Writer) error {
+	fs := flags("repl", stderr)
+	name := fs.String("storage", "file", fmt.Sprintf("storage kind: %s", strings.Join(repl.Storages(), ", ")))
+	dsn := fs.String("dsn", "", "data source name of the storage, a path for file and dir")
+	id := fs.String("id", "", "ID of the queue in the storage")
+
+	err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	if *dsn == "" {
+		return fmt.Errorf("-dsn is required: %w", errUsage)
+	}
+
+	storage, err := repl.Open(*name, *dsn)
+	if err != nil {
+		return err
+	}
+
+	defs := make([]definition.Document, 0, fs.NArg())
+	for _, path := range fs.Args() {
+		d, err := definition.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		defs = append(defs, d)
+	}
+
+	s := repl.New(storage, *id, defs, stdout)
+
+	err = s.Load()
+	if err != nil {
+		return err
+	}
+
+	return s.Run(stdin)
+}
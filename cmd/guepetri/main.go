@@ -0,0 +1,136 @@
+// Command guepetri validates, analyzes, renders and simulates workflow
+// definitions, and steps through stored queues in a REPL.
+//
+//	guepetri validate order.yaml refund.json
+//	guepetri analyze order.yaml
+//	guepetri render -format svg -o order.svg order.yaml
+//	guepetri simulate -expect done order.yaml pay ship
+//	guepetri repl -storage dir -dsn ./state -id user1 order.yaml
+//
+// Definitions are loaded with the default handlers of the registry package:
+// a place fires the transition named as the signal. The handler code of the
+// application is unknown to the tool, so every guard accepts all signals.
+//
+// It exits with 1 when a definition is invalid or has problems and with 2 on
+// wrong usage, so it fits pre-commit checks.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/uzh13/GuePetri/pkg/petri/definition"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+var errUsage = errors.New("invalid usage")
+
+type command struct {
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) error
+}
+
+var commands = map[string]command{
+	"validate": {usage: "validate FILE...", run: cmdValidate},
+	"analyze":  {usage: "analyze [-limit N] FILE...", run: cmdAnalyze},
+	"render":   {usage: "render [-format dot|mermaid|plantuml|svg] [-current] [-o OUT] FILE", run: cmdRender},
+	"simulate": {usage: "simulate [-expect PLACE] FILE SIGNAL...", run: cmdSimulate},
+	"repl":     {usage: "repl [-storage NAME] -dsn DSN -id ID FILE...", run: cmdREPL},
+}
+
+// stdin feeds the repl command.
+var stdin io.Reader = os.Stdin
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+
+		return 2
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "guepetri: unknown command %q\n", args[0])
+		usage(stderr)
+
+		return 2
+	}
+
+	err := cmd.run(args[1:], stdout, stderr)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		if err != errUsage {
+			fmt.Fprintf(stderr, "guepetri %s: %v\n", args[0], err)
+		}
+
+		fmt.Fprintf(stderr, "usage: guepetri %s\n", cmd.usage)
+
+		return 2
+	default:
+		fmt.Fprintf(stderr, "guepetri %s: %v\n", args[0], err)
+
+		return 1
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: guepetri COMMAND [ARGS]")
+	for _, name := range slices.Sorted(maps.Keys(commands)) {
+		fmt.Fprintf(w, "  guepetri %s\n", commands[name].usage)
+	}
+}
+
+// flags returns a flag set reporting errors to stderr instead of exiting.
+func flags(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	return fs
+}
+
+// parse parses flags and checks the number of positional arguments.
+func parse(fs *flag.FlagSet, args []string, least int) error {
+	err := fs.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+
+		// The flag set has reported the error already.
+		return errUsage
+	}
+
+	if fs.NArg() < least {
+		return errUsage
+	}
+
+	return nil
+}
+
+// load reads a definition and builds its graph with default handlers.
+func load(path string) (definition.Document, *graph.Petri[string, string], error) {
+	d, err := definition.ReadFile(path)
+	if err != nil {
+		return d, nil, err
+	}
+
+	g, err := definition.Build(d)
+	if err != nil {
+		return d, nil, err
+	}
+
+	return d, g, nil
+}
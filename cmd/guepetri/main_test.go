@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const orderYAML = `id: order
+start: new
+finish: done
+places:
+  - id: new
+  - id: paid
+  - id: done
+transitions:
+  - id: pay
+    guard: has_funds
+  - id: ship
+arcs:
+  - {from: new, to: pay}
+  - {from: pay, to: paid}
+  - {from: paid, to: ship}
+  - {from: ship, to: done}
+`
+
+const stuckJSON = `{
+  "id": "stuck",
+  "start": "new",
+  "finish": "done",
+  "places": [{"id": "new"}, {"id": "held"}, {"id": "done"}],
+  "transitions": [{"id": "hold"}, {"id": "close"}],
+  "arcs": [
+    {"from": "new", "to": "hold"},
+    {"from": "hold", "to": "held"},
+    {"from": "close", "to": "done"}
+  ]
+}`
+
+func write(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func runArgs(args ...string) (int, string, string) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	code := run(args, &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runArgs()
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "guepetri validate FILE...")
+
+	code, _, stderr = runArgs("lint")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "lint"`)
+
+	code, _, stderr = runArgs("simulate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "usage: guepetri simulate")
+}
+
+func TestValidate(t *testing.T) {
+	order := write(t, "order.yaml", orderYAML)
+	broken := write(t, "broken.yaml", "id: broken\nstart: new\nfinish: done\n")
+
+	code, stdout, _ := runArgs("validate", order)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, order+": ok\n", stdout)
+
+	code, stdout, stderr := runArgs("validate", order, broken)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, broken+": net broken: invalid net")
+	assert.Contains(t, stderr, "1 of 2: invalid definitions")
+}
+
+func TestAnalyze(t *testing.T) {
+	code, stdout, _ := runArgs("analyze", write(t, "order.yaml", orderYAML))
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "bound:       1\n")
+
+	code, stdout, stderr := runArgs("analyze", write(t, "stuck.json", stuckJSON))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "unreachable: done, close\n")
+	assert.Contains(t, stdout, "dead ends:   held\n")
+	assert.Contains(t, stderr, "problems found")
+}
+
+func TestRender(t *testing.T) {
+	order := write(t, "order.yaml", orderYAML)
+
+	code, stdout, _ := runArgs("render", "-format", "mermaid", order)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "flowchart LR")
+
+	out := filepath.Join(t.TempDir(), "order.dot")
+	code, stdout, _ = runArgs("render", "-o", out, order)
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+
+	dot, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Contains(t, string(dot), "digraph")
+
+	code, _, stderr := runArgs("render", "-format", "png", order)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown format "png"`)
+	assert.Contains(t, stderr, "usage: guepetri render")
+}
+
+func TestSimulate(t *testing.T) {
+	order := write(t, "order.yaml", orderYAML)
+
+	code, stdout, _ := runArgs("simulate", "-expect", "done", order, "ship", "pay", "ship")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, `start    new
+ignore   ship at new
+fire     pay: new -> pay -> paid
+fire     ship: paid -> ship -> done
+finish   done
+`, stdout)
+
+	code, _, stderr := runArgs("simulate", "-expect", "done", order, "pay")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "graph ended on paid, expected done")
+
+	code, _, stderr = runArgs("simulate", order, "pay", "ship", "pay")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "graph finished with 1 signals left: [pay]")
+}
+
+func TestREPL(t *testing.T) {
+	order := write(t, "order.yaml", orderYAML)
+	state := filepath.Join(t.TempDir(), "state.json")
+
+	code, _, stderr := runArgs("repl", order)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "-dsn is required")
+
+	code, _, stderr = runArgs("repl", "-storage", "redis", "-dsn", "localhost", order)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `"redis": unknown storage`)
+
+	stdin = strings.NewReader("ls\nsave\n")
+	t.Cleanup(func() { stdin = os.Stdin })
+
+	code, stdout, _ := runArgs("repl", "-dsn", state, "-id", "user1", order)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "> queue is empty\n> > \n", stdout)
+
+	data, err := os.ReadFile(state)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"objects": {}`)
+}
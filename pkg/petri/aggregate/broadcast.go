@@ -0,0 +1,137 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+type BroadcastStatus int
+
+const (
+	// BroadcastHandled means the graph fired a transition on the signal.
+	BroadcastHandled BroadcastStatus = iota
+	// BroadcastIgnored means the current place returned graph.ErrSignalIgnored.
+	BroadcastIgnored
+	// BroadcastBuffered means the graph is not started yet or has buffered
+	// signals, the signal went to its mailbox after them.
+	BroadcastBuffered
+	// BroadcastFailed means the graph returned an error, see BroadcastResult.Err.
+	BroadcastFailed
+	// BroadcastDeadLettered means the error policy moved the failed graph to
+	// the dead letter list.
+	BroadcastDeadLettered
+)
+
+func (s BroadcastStatus) String() string {
+	switch s {
+	case BroadcastHandled:
+		return "handled"
+	case BroadcastIgnored:
+		return "ignored"
+	case BroadcastBuffered:
+		return "buffered"
+	case BroadcastFailed:
+		return "failed"
+	case BroadcastDeadLettered:
+		return "dead lettered"
+	default:
+		return fmt.Sprintf("BroadcastStatus(%d)", int(s))
+	}
+}
+
+type BroadcastResult struct {
+	Status   BroadcastStatus
+	Level    int
+	Finished bool
+	Err      error
+}
+
+// Broadcast offers the signal to every queued graph without changing focus.
+// Started graphs act on it under the error policy, the ones without focus are
+// resumed for the signal and suspended again. Graphs that are not started yet
+// or have buffered signals get it into their mailbox. A failing graph does not
+// stop the broadcast, the joined error of all failures is returned together
+// with the result for every graph ID.
+func (p *PetriQueue[T, V]) Broadcast(signal T) (map[V]BroadcastResult, error) {
+	return p.BroadcastContext(context.Background(), signal)
+}
+
+func (p *PetriQueue[T, V]) BroadcastContext(ctx context.Context, signal T) (map[V]BroadcastResult, error) {
+	results := make(map[V]BroadcastResult)
+	if p.queue == nil {
+		return results, nil
+	}
+
+	type target struct {
+		level int
+		graph *graph.Petri[T, V]
+	}
+
+	var targets []target
+	p.queue.Range(func(level int, g *graph.Petri[T, V]) bool {
+		targets = append(targets, target{level: level, graph: g})
+
+		return true
+	})
+
+	focused, _, _, err := p.focus(ctx)
+	if err != nil {
+		return results, fmt.Errorf("unable to focus graph: %w", err)
+	}
+
+	var errs []error
+	for _, t := range targets {
+		result := p.broadcastTo(p.context(ctx), t.graph, t.graph == focused, t.level, signal)
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("broadcast to graph %v: %w", t.graph.ID, result.Err))
+		}
+
+		results[t.graph.ID] = result
+	}
+
+	if focused != nil && (results[focused.ID].Finished || results[focused.ID].Status == BroadcastDeadLettered) {
+		err = p.ActContext(ctx, p.zeroSignal)
+		if err != nil && !errors.Is(err, graph.ErrSignalIgnored) {
+			errs = append(errs, fmt.Errorf("act after broadcast with zero signal: %w", err))
+		}
+	}
+
+	return results, errors.Join(errs...)
+}
+
+func (p *PetriQueue[T, V]) broadcastTo(ctx context.Context, target *graph.Petri[T, V], focused bool, level int, signal T) BroadcastResult {
+	// the signal queues up behind the buffered ones to keep their order
+	if target.Current == nil || len(target.Mailbox) > 0 {
+		err := p.buffer(target, signal)
+		if err != nil {
+			return BroadcastResult{Status: BroadcastFailed, Level: level, Err: err}
+		}
+
+		return BroadcastResult{Status: BroadcastBuffered, Level: level}
+	}
+
+	var (
+		finished, buried bool
+		err              error
+	)
+
+	if focused {
+		finished, buried, err = p.deliver(ctx, target, level, signal)
+	} else {
+		finished, buried, err = p.actOffTurn(ctx, target, level, signal)
+	}
+
+	switch {
+	case errors.Is(err, graph.ErrSignalIgnored):
+		return BroadcastResult{Status: BroadcastIgnored, Level: level}
+	case err != nil:
+		return BroadcastResult{Status: BroadcastFailed, Level: level, Err: err}
+	case buried:
+		return BroadcastResult{Status: BroadcastDeadLettered, Level: level}
+	default:
+		return BroadcastResult{Status: BroadcastHandled, Level: level, Finished: finished}
+	}
+}
@@ -0,0 +1,132 @@
+package aggregate_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+type ignoringPlaceHandler struct {
+	placeHandler
+}
+
+func (h *ignoringPlaceHandler) ChooseTo(string) (*graph.Transition[string, string], error) {
+	return nil, graph.ErrSignalIgnored
+}
+
+func TestPetriQueue_Broadcast(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	graph1 := makeGraph1(&b)
+	graph1.Start.Handler = &ignoringPlaceHandler{placeHandler{buffer: &b, graphName: "graph1", placeName: "start"}}
+	graph1.Handler = &suspendingGraphHandler{graphHandler{buffer: &b, graphName: "graph1"}}
+
+	err := target.AddGraph(0, graph1)
+	if err != nil {
+		t.Errorf("failed to add first graph")
+	}
+
+	err = target.AddGraph(1, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add second graph")
+	}
+
+	results, err := target.Broadcast("shutdown")
+	if err != nil {
+		t.Errorf("broadcast: %v", err)
+	}
+
+	if results["graph1"].Status != aggregate.BroadcastIgnored {
+		t.Errorf("graph1 expected to ignore signal, got %v", results["graph1"].Status)
+	}
+
+	if !graph1.Suspended {
+		t.Errorf("graph1 expected to stay suspended")
+	}
+
+	if strings.Contains(b.Result(), "resume graph graph1") {
+		t.Errorf("graph1 expected not to be resumed for an ignored signal, got: %s", b.Result())
+	}
+
+	if results["graph2"].Status != aggregate.BroadcastHandled || results["graph2"].Level != 1 {
+		t.Errorf("graph2 expected to handle signal on level 1, got %+v", results["graph2"])
+	}
+}
+
+func TestPetriQueue_Broadcast_FinishAndBuffer(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnReject)
+
+	results, err := target.Broadcast("reload")
+	if err != nil {
+		t.Errorf("broadcast: %v", err)
+	}
+
+	if results["graph1"].Status != aggregate.BroadcastHandled || !results["graph1"].Finished {
+		t.Errorf("graph1 expected to finish, got %+v", results["graph1"])
+	}
+
+	if results["graph2"].Status != aggregate.BroadcastBuffered {
+		t.Errorf("graph2 expected to buffer signal, got %v", results["graph2"].Status)
+	}
+
+	if _, _, ok := target.FindGraph("graph2"); ok {
+		t.Errorf("graph2 expected to finish on buffered and zero signals")
+	}
+}
+
+func TestPetriQueue_Broadcast_BehindMailbox(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnBuffer)
+
+	graph1, _, _ := target.FindGraph("graph1")
+	graph1.Start.Handler = &ignoringPlaceHandler{placeHandler{buffer: &b, graphName: "graph1", placeName: "start"}}
+
+	graph2, _, _ := target.FindGraph("graph2")
+	graph2.Current = graph2.Start
+
+	err := target.ActOn("graph2", "b1")
+	if err != nil {
+		t.Fatalf("act on graph2: %v", err)
+	}
+
+	results, err := target.Broadcast("reload")
+	if err != nil {
+		t.Errorf("broadcast: %v", err)
+	}
+
+	if results["graph2"].Status != aggregate.BroadcastBuffered {
+		t.Errorf("graph2 expected to buffer signal behind its mailbox, got %v", results["graph2"].Status)
+	}
+
+	if len(graph2.Mailbox) != 2 || graph2.Mailbox[0] != "b1" || graph2.Mailbox[1] != "reload" {
+		t.Errorf("expected broadcast after buffered signal, got %v", graph2.Mailbox)
+	}
+}
+
+func TestPetriQueue_Broadcast_ErrorPolicy(t *testing.T) {
+	b := buffer{current: "\n"}
+	target, _ := newFailingQueue(t, &b, -1)
+	target.SetErrorPolicy(aggregate.ErrorPolicy{Retries: 1, DeadLetter: true})
+
+	results, err := target.Broadcast("reload")
+	if err != nil {
+		t.Errorf("broadcast: %v", err)
+	}
+
+	if results["graph1"].Status != aggregate.BroadcastDeadLettered {
+		t.Errorf("graph1 expected to be dead lettered, got %+v", results["graph1"])
+	}
+
+	if len(target.DeadLetters()) != 1 {
+		t.Errorf("expected one dead letter, got %v", target.DeadLetters())
+	}
+
+	if _, _, ok := target.FindGraph("graph2"); ok {
+		t.Errorf("graph2 expected to get focus and finish on buffered and zero signals")
+	}
+}
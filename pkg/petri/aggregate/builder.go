@@ -10,6 +10,11 @@ type Storage[T any, V comparable, U comparable] interface {
 	Get(U) (*priority.Queue[T, V], error)
 }
 
+// Saver is an optional Storage extension writing the state back.
+type Saver[T any, V comparable, U comparable] interface {
+	Save(U, *priority.Queue[T, V]) error
+}
+
 type Builder[T any, V comparable, U comparable] struct {
 	ID      U
 	Storage Storage[T, V, U]
@@ -41,3 +46,19 @@ func (b *Builder[T, V, U]) LoadState() error {
 func (b *Builder[T, V, U]) Build(zeroSignal T) *PetriQueue[T, V] {
 	return NewPetriQueue(b.petriQ, zeroSignal)
 }
+
+// SaveState writes the state of the queue back to the storage. It fails with
+// ErrReadOnly when the storage does not implement Saver.
+func (b *Builder[T, V, U]) SaveState(q *PetriQueue[T, V]) error {
+	saver, ok := b.Storage.(Saver[T, V, U])
+	if !ok {
+		return fmt.Errorf("unable to save state: %w", ErrReadOnly)
+	}
+
+	err := saver.Save(b.ID, q.GetQueue())
+	if err != nil {
+		return fmt.Errorf("unable to save state: %w", err)
+	}
+
+	return nil
+}
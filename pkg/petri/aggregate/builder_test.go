@@ -16,6 +16,17 @@ func (s *StorageMock) Get(_ string) (*priority.Queue[string, string], error) {
 	return s.q, nil
 }
 
+type SaverMock struct {
+	StorageMock
+	saved map[string]*priority.Queue[string, string]
+}
+
+func (s *SaverMock) Save(id string, q *priority.Queue[string, string]) error {
+	s.saved[id] = q
+
+	return nil
+}
+
 func TestBuilder(t *testing.T) {
 	tests := []struct {
 		name string
@@ -46,3 +57,17 @@ func TestBuilder(t *testing.T) {
 		})
 	}
 }
+
+func TestBuilder_SaveState(t *testing.T) {
+	readOnly := aggregate.NewBuilder[string, string, string]("user1", &StorageMock{})
+	assert.NoError(t, readOnly.LoadState())
+	assert.ErrorIs(t, readOnly.SaveState(readOnly.Build("0")), aggregate.ErrReadOnly)
+
+	storage := &SaverMock{saved: map[string]*priority.Queue[string, string]{}}
+	target := aggregate.NewBuilder[string, string, string]("user1", storage)
+	assert.NoError(t, target.LoadState())
+
+	q := target.Build("0")
+	assert.NoError(t, target.SaveState(q))
+	assert.Same(t, q.GetQueue(), storage.saved["user1"])
+}
@@ -0,0 +1,45 @@
+package aggregate
+
+import (
+	"fmt"
+)
+
+// DefaultMaxCascade limits zero signal deliveries in one Act call unless SetMaxCascade is used.
+const DefaultMaxCascade = 1024
+
+type ActReport[V comparable] struct {
+	// Completed lists graphs finished during the call in the order of completion
+	Completed []V
+	// DeadLettered lists graphs moved to the dead letter list by the error policy
+	DeadLettered []V
+	// Cascade counts zero signal deliveries after completed or dead lettered graphs
+	Cascade int
+}
+
+type CascadeLimitError[V comparable] struct {
+	Limit     int
+	Completed []V
+}
+
+func (e *CascadeLimitError[V]) Error() string {
+	return fmt.Sprintf("%v: limit %d, completed graphs %v", ErrCascadeLimit, e.Limit, e.Completed)
+}
+
+func (e *CascadeLimitError[V]) Is(target error) bool {
+	return target == ErrCascadeLimit
+}
+
+// SetMaxCascade limits zero signal deliveries in one Act call, non-positive value restores DefaultMaxCascade.
+func (p *PetriQueue[T, V]) SetMaxCascade(limit int) *PetriQueue[T, V] {
+	p.cascadeCap = limit
+
+	return p
+}
+
+func (p *PetriQueue[T, V]) maxCascade() int {
+	if p.cascadeCap <= 0 {
+		return DefaultMaxCascade
+	}
+
+	return p.cascadeCap
+}
@@ -0,0 +1,54 @@
+package aggregate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+func newChainQueue(t *testing.T, b *buffer, ids ...string) *aggregate.PetriQueue[string, string] {
+	t.Helper()
+
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+	for _, id := range ids {
+		g := makeGraph1(b)
+		g.ID = id
+
+		err := target.AddGraph(0, g)
+		if err != nil {
+			t.Fatalf("failed to add graph %s", id)
+		}
+	}
+
+	return target
+}
+
+func TestPetriQueue_ActReport(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newChainQueue(t, &b, "a", "b", "c")
+
+	report, err := target.ActReport("sig")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, report.Completed)
+	assert.Equal(t, 2, report.Cascade)
+}
+
+func TestPetriQueue_ActReport_CascadeLimit(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newChainQueue(t, &b, "a", "b", "c", "d").SetMaxCascade(1)
+
+	report, err := target.ActReport("sig")
+	assert.True(t, errors.Is(err, aggregate.ErrCascadeLimit))
+
+	var limitErr *aggregate.CascadeLimitError[string]
+	assert.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, 1, limitErr.Limit)
+	assert.Equal(t, []string{"a", "b"}, limitErr.Completed)
+	assert.Equal(t, []string{"a", "b"}, report.Completed)
+
+	_, _, ok := target.FindGraph("c")
+	assert.True(t, ok)
+}
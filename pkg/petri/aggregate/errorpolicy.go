@@ -0,0 +1,159 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+// ErrorPolicy decides what Act does when the focused graph fails. The zero
+// value halts the queue: the error is returned and the graph keeps focus.
+type ErrorPolicy struct {
+	// Retries is the number of extra attempts to deliver the failed signal
+	Retries int
+	// Backoff returns the pause before the given retry, attempts start from 1
+	Backoff func(attempt int) time.Duration
+	// DeadLetter moves the graph to the dead letter list once retries are
+	// exhausted, focus passes to the next graph with the zero signal
+	DeadLetter bool
+}
+
+// ExponentialBackoff doubles the pause from base on every retry up to limit.
+func ExponentialBackoff(base, limit time.Duration) func(int) time.Duration {
+	return func(attempt int) time.Duration {
+		pause := base
+		for i := 1; i < attempt && pause < limit; i++ {
+			pause *= 2
+		}
+
+		return min(pause, limit)
+	}
+}
+
+func (p *PetriQueue[T, V]) SetErrorPolicy(policy ErrorPolicy) *PetriQueue[T, V] {
+	p.onError = policy
+
+	return p
+}
+
+// SetGraphErrorPolicy overrides the queue error policy for one graph.
+func (p *PetriQueue[T, V]) SetGraphErrorPolicy(id V, policy ErrorPolicy) *PetriQueue[T, V] {
+	if p.graphOnErr == nil {
+		p.graphOnErr = make(map[V]ErrorPolicy)
+	}
+
+	p.graphOnErr[id] = policy
+
+	return p
+}
+
+func (p *PetriQueue[T, V]) DeadLetters() []*priority.DeadLetter[T, V] {
+	if p.queue == nil {
+		return nil
+	}
+
+	return p.queue.Dead
+}
+
+// Requeue returns a dead lettered graph to its former level. The graph keeps
+// its current place and gets focus the same way AddGraph gives it.
+func (p *PetriQueue[T, V]) Requeue(id V) error {
+	return p.RequeueContext(context.Background(), id)
+}
+
+func (p *PetriQueue[T, V]) RequeueContext(ctx context.Context, id V) error {
+	if p.queue == nil {
+		return fmt.Errorf("graph %v in dead letters: %w", id, ErrGraphNotFound)
+	}
+
+	if _, _, found := p.queue.Find(id); found {
+		return fmt.Errorf("requeue graph %v: %w", id, ErrDuplicateGraph)
+	}
+
+	_, ok := p.queue.Revive(id)
+	if !ok {
+		return fmt.Errorf("graph %v in dead letters: %w", id, ErrGraphNotFound)
+	}
+
+	err := p.startFocused(p.context(ctx))
+	if err != nil {
+		return fmt.Errorf("requeue graph %v and start: %w", id, err)
+	}
+
+	return nil
+}
+
+// deliver acts on the graph applying the error policy. It reports whether the
+// graph finished or was moved to the dead letter list. An ignored signal is
+// not a failure of the graph and is returned as is. A graph that already stands
+// on its finish place retries only the finish stage.
+func (p *PetriQueue[T, V]) deliver(ctx context.Context, current *graph.Petri[T, V], level int, signal T) (bool, bool, error) {
+	policy, ok := p.graphOnErr[current.ID]
+	if !ok {
+		policy = p.onError
+	}
+
+	finished, err := p.actGraph(ctx, current, level, signal)
+	if errors.Is(err, graph.ErrSignalIgnored) {
+		return false, false, err
+	}
+
+	for attempt := 1; err != nil && attempt <= policy.Retries && ctx.Err() == nil; attempt++ {
+		if logger := p.logging(ctx, slog.LevelWarn); logger != nil {
+			logger.LogAttrs(ctx, slog.LevelWarn, "retrying signal",
+				slog.Any("graph", current.ID), slog.Int("priority", level), slog.Any("signal", signal),
+				slog.Int("attempt", attempt), slog.Any("error", err))
+		}
+
+		if policy.Backoff != nil {
+			ctxErr := p.pause(ctx, policy.Backoff(attempt))
+			if ctxErr != nil {
+				return false, false, fmt.Errorf("retrying signal %v of graph %v: %w", signal, current.ID, errors.Join(err, ctxErr))
+			}
+		}
+
+		if current.IsOnFinish() {
+			finished, err = p.finishGraph(p.graphContext(ctx, level), current, level, signal)
+
+			continue
+		}
+
+		finished, err = p.actGraph(ctx, current, level, signal)
+	}
+
+	if err == nil || !policy.DeadLetter {
+		return finished, false, err
+	}
+
+	_, ok = p.queue.Bury(current.ID, err)
+	if !ok {
+		return false, false, fmt.Errorf("unable to move graph %v to dead letters: %w", current.ID, err)
+	}
+
+	if logger := p.logging(ctx, slog.LevelError); logger != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "graph dead lettered",
+			slog.Any("graph", current.ID), slog.Int("priority", level), slog.Any("signal", signal), slog.Any("error", err))
+	}
+
+	suspendErr := current.SuspendContext(p.graphContext(ctx, level))
+	if suspendErr != nil {
+		return false, true, fmt.Errorf("suspending dead lettered graph %v: %w", current.ID, suspendErr)
+	}
+
+	return false, true, nil
+}
+
+// pause waits for the backoff unless the context is done first.
+func (p *PetriQueue[T, V]) pause(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.after(d):
+		return nil
+	}
+}
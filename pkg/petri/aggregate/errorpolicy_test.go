@@ -0,0 +1,249 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+type failingPlaceHandler struct {
+	placeHandler
+	fails int
+}
+
+func (h *failingPlaceHandler) ChooseTo(signal string) (*graph.Transition[string, string], error) {
+	if h.fails != 0 {
+		h.fails--
+
+		return nil, errors.New("choose failed")
+	}
+
+	return h.placeHandler.ChooseTo(signal)
+}
+
+func newFailingQueue(t *testing.T, b *buffer, fails int) (*aggregate.PetriQueue[string, string], *failingPlaceHandler) {
+	t.Helper()
+
+	graph1 := makeGraph1(b)
+	failing := &failingPlaceHandler{placeHandler: *graph1.Start.Handler.(*placeHandler), fails: fails}
+	graph1.Start.Handler = failing
+
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	err := target.AddGraph(0, graph1)
+	if err != nil {
+		t.Fatalf("failed to add first graph")
+	}
+
+	err = target.AddGraph(0, makeGraph2(b))
+	if err != nil {
+		t.Fatalf("failed to add second graph")
+	}
+
+	return target, failing
+}
+
+func TestPetriQueue_ErrorPolicy_Halt(t *testing.T) {
+	b := buffer{current: "\n"}
+	target, _ := newFailingQueue(t, &b, 1)
+
+	err := target.Act("sig")
+	assert.Error(t, err)
+
+	head, _, ok := target.GetQueue().Peek()
+	assert.True(t, ok)
+	assert.Equal(t, "graph1", head.ID)
+	assert.Empty(t, target.DeadLetters())
+}
+
+func TestPetriQueue_ErrorPolicy_Retry(t *testing.T) {
+	b := buffer{current: "\n"}
+	target, _ := newFailingQueue(t, &b, 2)
+	target.SetErrorPolicy(aggregate.ErrorPolicy{Retries: 2})
+
+	report, err := target.ActReport("sig")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"graph1"}, report.Completed)
+}
+
+func TestPetriQueue_ErrorPolicy_Backoff(t *testing.T) {
+	b := buffer{current: "\n"}
+	target, _ := newFailingQueue(t, &b, 2)
+
+	var pauses []time.Duration
+	target.SetErrorPolicy(aggregate.ErrorPolicy{
+		Retries: 2,
+		Backoff: aggregate.ExponentialBackoff(10*time.Millisecond, time.Second),
+	}).SetAfter(func(d time.Duration) <-chan time.Time {
+		pauses = append(pauses, d)
+
+		fired := make(chan time.Time, 1)
+		fired <- time.Time{}
+
+		return fired
+	})
+
+	_, err := target.ActReport("sig")
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, pauses)
+}
+
+func TestPetriQueue_ErrorPolicy_BackoffCancelled(t *testing.T) {
+	b := buffer{current: "\n"}
+	target, _ := newFailingQueue(t, &b, -1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	target.SetErrorPolicy(aggregate.ErrorPolicy{
+		Retries:    3,
+		Backoff:    aggregate.ExponentialBackoff(time.Hour, time.Hour),
+		DeadLetter: true,
+	}).SetAfter(func(time.Duration) <-chan time.Time {
+		cancel()
+
+		return make(chan time.Time)
+	})
+
+	err := target.ActContext(ctx, "sig")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorContains(t, err, "choose failed")
+	assert.Empty(t, target.DeadLetters())
+
+	head, _, ok := target.GetQueue().Peek()
+	assert.True(t, ok)
+	assert.Equal(t, "graph1", head.ID)
+}
+
+func TestPetriQueue_ErrorPolicy_DeadLetter(t *testing.T) {
+	b := buffer{current: "\n"}
+	target, failing := newFailingQueue(t, &b, -1)
+	target.SetGraphErrorPolicy("graph1", aggregate.ErrorPolicy{Retries: 1, DeadLetter: true})
+
+	report, err := target.ActReport("sig")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"graph1"}, report.DeadLettered)
+	assert.Equal(t, 1, report.Cascade)
+
+	dead := target.DeadLetters()
+	assert.Len(t, dead, 1)
+	assert.Equal(t, "graph1", dead[0].Graph.ID)
+	assert.Contains(t, dead[0].Error, "choose failed")
+
+	graph2, _, ok := target.FindGraph("graph2")
+	assert.True(t, ok)
+	assert.Equal(t, "middle", graph2.Current.ID)
+
+	failing.fails = 0
+	err = target.Requeue("graph1")
+	assert.NoError(t, err)
+	assert.Empty(t, target.DeadLetters())
+	assert.Error(t, target.Requeue("graph1"))
+
+	report, err = target.ActReport("sig")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"graph2", "graph1"}, report.Completed)
+}
+
+type failingGraphHandler struct {
+	graphHandler
+	fails int
+}
+
+func (h *failingGraphHandler) HandleOut() error {
+	if h.fails != 0 {
+		h.fails--
+
+		return errors.New("handle out failed")
+	}
+
+	return h.graphHandler.HandleOut()
+}
+
+func TestPetriQueue_ErrorPolicy_RetryFinish(t *testing.T) {
+	b := buffer{current: "\n"}
+	graph1 := makeGraph1(&b)
+	graph1.Handler = &failingGraphHandler{graphHandler: *graph1.Handler.(*graphHandler), fails: 1}
+
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+		SetErrorPolicy(aggregate.ErrorPolicy{Retries: 1, DeadLetter: true})
+	assert.NoError(t, target.AddGraph(0, graph1))
+
+	report, err := target.ActReport("sig")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"graph1"}, report.Completed)
+	assert.Empty(t, target.DeadLetters())
+	assert.Equal(t, 1, strings.Count(b.current, "ChooseTo at start"))
+	assert.Contains(t, b.current, "handle out graph graph1")
+}
+
+func TestPetriQueue_ErrorPolicy_CascadeIgnored(t *testing.T) {
+	for name, policy := range map[string]aggregate.ErrorPolicy{
+		"halt":        {},
+		"dead letter": {Retries: 1, DeadLetter: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b := buffer{current: "\n"}
+			graph2 := makeGraph2(&b)
+			graph2.Start.Handler = &selectivePlaceHandler{placeHandler: *graph2.Start.Handler.(*placeHandler), ignore: "0"}
+
+			target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+				SetErrorPolicy(policy)
+			assert.NoError(t, target.AddGraph(0, makeGraph1(&b)))
+			assert.NoError(t, target.AddGraph(0, graph2))
+
+			report, err := target.ActReport("sig")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"graph1"}, report.Completed)
+			assert.Empty(t, target.DeadLetters())
+
+			head, _, ok := target.GetQueue().Peek()
+			assert.True(t, ok)
+			assert.Equal(t, "graph2", head.ID)
+			assert.Equal(t, "start", head.Current.ID)
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := aggregate.ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	assert.Equal(t, 10*time.Millisecond, backoff(1))
+	assert.Equal(t, 20*time.Millisecond, backoff(2))
+	assert.Equal(t, 40*time.Millisecond, backoff(3))
+	assert.Equal(t, 50*time.Millisecond, backoff(4))
+}
+
+type panickingPlaceHandler struct {
+	placeHandler
+}
+
+func (h *panickingPlaceHandler) ChooseTo(string) (*graph.Transition[string, string], error) {
+	panic("handler bug")
+}
+
+func TestPetriQueue_ErrorPolicy_Panic(t *testing.T) {
+	b := buffer{current: "\n"}
+	graph1 := makeGraph1(&b)
+	graph1.Start.Handler = &panickingPlaceHandler{*graph1.Start.Handler.(*placeHandler)}
+
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+	assert.NoError(t, target.AddGraph(0, graph1))
+
+	err := target.Act("sig")
+	assert.True(t, errors.Is(err, graph.ErrPanic))
+
+	target.SetErrorPolicy(aggregate.ErrorPolicy{DeadLetter: true})
+
+	report, err := target.ActReport("sig")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"graph1"}, report.DeadLettered)
+	assert.Contains(t, target.DeadLetters()[0].Error, "handler bug")
+}
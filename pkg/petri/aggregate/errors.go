@@ -0,0 +1,15 @@
+package aggregate
+
+import (
+	"errors"
+)
+
+var (
+	ErrQueueEmpty     = errors.New("queue is empty")
+	ErrGraphNotFound  = errors.New("graph not found")
+	ErrDuplicateGraph = errors.New("graph is already queued")
+	ErrGraphNotActive = errors.New("graph is not active")
+	ErrMailboxFull    = errors.New("mailbox is full")
+	ErrCascadeLimit   = errors.New("zero signal cascade limit exceeded")
+	ErrReadOnly       = errors.New("storage is read only")
+)
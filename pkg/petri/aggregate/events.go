@@ -0,0 +1,67 @@
+package aggregate
+
+import (
+	"context"
+	"time"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// Subscribe calls fn synchronously for every event of the queue and of its
+// graphs until the returned cancel function is called.
+func (p *PetriQueue[T, V]) Subscribe(fn func(graph.Event[T, V])) func() {
+	return p.bus().Subscribe(fn)
+}
+
+// SubscribeChan delivers events of the queue and of its graphs to a buffered
+// channel.
+func (p *PetriQueue[T, V]) SubscribeChan(size int, policy graph.OverflowPolicy) (<-chan graph.Event[T, V], func()) {
+	return p.bus().SubscribeChan(size, policy)
+}
+
+func (p *PetriQueue[T, V]) bus() *graph.Bus[T, V] {
+	if p.events == nil {
+		p.events = &graph.Bus[T, V]{}
+	}
+
+	return p.events
+}
+
+// eventContext passes events of a graph acting on the level to the queue
+// subscribers.
+func (p *PetriQueue[T, V]) eventContext(ctx context.Context, level int) context.Context {
+	if p.events == nil {
+		return ctx
+	}
+
+	return graph.WithEventSink(ctx, func(e graph.Event[T, V]) {
+		e.Level = level
+		p.events.Publish(e)
+	})
+}
+
+func (p *PetriQueue[T, V]) emitAdded(target *graph.Petri[T, V], level int) {
+	if p.events == nil {
+		return
+	}
+
+	e := graph.Event[T, V]{Kind: graph.EventGraphAdded, At: time.Now(), GraphID: target.ID, Level: level}
+	if target.Current != nil {
+		e.Place = target.Current.ID
+	}
+
+	p.events.Publish(e)
+}
+
+func (p *PetriQueue[T, V]) emitPreempted(active *graph.Petri[T, V], level int, head *graph.Petri[T, V]) {
+	if p.events == nil {
+		return
+	}
+
+	e := graph.Event[T, V]{Kind: graph.EventGraphPreempted, At: time.Now(), GraphID: active.ID, Level: level, To: head.ID}
+	if active.Current != nil {
+		e.Place = active.Current.ID
+	}
+
+	p.events.Publish(e)
+}
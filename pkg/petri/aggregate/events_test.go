@@ -0,0 +1,39 @@
+package aggregate_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+func TestPetriQueue_Subscribe(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	var events []string
+	target.Subscribe(func(e graph.Event[string, string]) {
+		events = append(events, fmt.Sprintf("%v %s %d %s%s", e.Kind, e.GraphID, e.Level, e.Place, e.Transition))
+	})
+
+	assert.NoError(t, target.AddGraph(0, makeGraph1(&b)))
+	assert.NoError(t, target.AddGraph(1, makeGraph2(&b)))
+	assert.NoError(t, target.Act("sig"))
+
+	assert.Equal(t, []string{
+		"graph_added graph1 0 ",
+		"graph_started graph1 0 start",
+		"place_entered graph1 0 start",
+		"graph_added graph2 1 ",
+		"graph_preempted graph1 0 start",
+		"graph_started graph2 1 start",
+		"place_entered graph2 1 start",
+		"place_left graph2 1 start",
+		"transition_fired graph2 1 start_to_finish",
+		"place_entered graph2 1 middle",
+	}, events)
+}
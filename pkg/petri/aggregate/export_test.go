@@ -0,0 +1,10 @@
+package aggregate
+
+import "time"
+
+// SetAfter replaces the timer of retry backoffs.
+func (p *PetriQueue[T, V]) SetAfter(after func(time.Duration) <-chan time.Time) *PetriQueue[T, V] {
+	p.after = after
+
+	return p
+}
@@ -0,0 +1,55 @@
+package aggregate
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// SetLogger enables structured logging of preemption, retries and dead
+// letters. Graphs without a logger of their own log through it as well, with
+// the priority level attached. Logging is off while the logger is nil.
+func (p *PetriQueue[T, V]) SetLogger(logger *slog.Logger) *PetriQueue[T, V] {
+	p.logger = logger
+
+	return p
+}
+
+// graphContext passes the priority level, the queue logger and the event
+// subscribers to a graph acting on the level.
+func (p *PetriQueue[T, V]) graphContext(ctx context.Context, level int) context.Context {
+	ctx = p.eventContext(ctx, level)
+
+	if len(p.intercept) != 0 {
+		ctx = context.WithValue(ctx, priorityKey{}, level)
+	}
+
+	// Graphs log errors at most, a logger dropping them is not passed on, so
+	// acting does not pay for a logger that writes nothing.
+	if p.logger == nil || !p.logger.Enabled(ctx, slog.LevelError) {
+		return ctx
+	}
+
+	return graph.WithLogger(ctx, p.logger.With(slog.Int("priority", level)))
+}
+
+type priorityKey struct{}
+
+// PriorityFrom returns the priority level of the graph making the handler
+// call. It is available to interceptors added with PetriQueue.Use.
+func PriorityFrom(ctx context.Context) (int, bool) {
+	level, ok := ctx.Value(priorityKey{}).(int)
+
+	return level, ok
+}
+
+// logging returns the logger to use for the record or nil when the record is
+// dropped, so callers build attributes only for enabled records.
+func (p *PetriQueue[T, V]) logging(ctx context.Context, level slog.Level) *slog.Logger {
+	if p.logger == nil || !p.logger.Enabled(ctx, level) {
+		return nil
+	}
+
+	return p.logger
+}
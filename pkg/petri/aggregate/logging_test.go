@@ -0,0 +1,123 @@
+package aggregate_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+var durations = regexp.MustCompile(`duration=[^ \n]+`)
+
+func newTestLogger(out *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+}
+
+func TestPetriQueue_SetLogger(t *testing.T) {
+	b := buffer{current: "\n"}
+	out := bytes.Buffer{}
+	expected := `level=INFO msg="graph started" priority=0 graph=graph1 place=start
+level=INFO msg="graph preempted" graph=graph1 priority=0 by=graph2 by_priority=1
+level=INFO msg="graph started" priority=1 graph=graph2 place=start
+level=DEBUG msg="transition fired" priority=1 graph=graph2 signal=sig from=start duration=0s transition=start_to_finish to=middle
+`
+
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+		SetLogger(newTestLogger(&out))
+
+	err := target.AddGraph(0, makeGraph1(&b))
+	if err != nil {
+		t.Fatalf("add graph1: %v", err)
+	}
+
+	err = target.AddGraph(1, makeGraph2(&b))
+	if err != nil {
+		t.Fatalf("add graph2: %v", err)
+	}
+
+	err = target.Act("sig")
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+
+	result := durations.ReplaceAllString(out.String(), "duration=0s")
+	if expected != result {
+		t.Errorf("expected: %s, got: %s", expected, result)
+	}
+}
+
+func TestPetriQueue_SetLogger_DeadLetter(t *testing.T) {
+	b := buffer{current: "\n"}
+	out := bytes.Buffer{}
+
+	target, _ := newFailingQueue(t, &b, -1)
+	target.SetLogger(newTestLogger(&out)).
+		SetErrorPolicy(aggregate.ErrorPolicy{Retries: 1, DeadLetter: true})
+
+	_, err := target.ActReport("sig")
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+
+	for _, record := range []string{
+		`level=ERROR msg="firing failed" priority=0 graph=graph1 signal=sig from=start`,
+		`level=WARN msg="retrying signal" graph=graph1 priority=0 signal=sig attempt=1`,
+		`level=ERROR msg="graph dead lettered" graph=graph1 priority=0 signal=sig`,
+	} {
+		if !strings.Contains(out.String(), record) {
+			t.Errorf("expected record %s, got: %s", record, out.String())
+		}
+	}
+}
+
+func TestPetriQueue_SetLogger_Disabled(t *testing.T) {
+	allocs := func(logger *slog.Logger) float64 {
+		b := buffer{}
+
+		idle := graph.NewPlace[string, string]("idle", nil)
+		tick := graph.NewTransition[string, string]("tick", &transitionHandler{next: idle, buffer: &b, graphName: "loop", transitionName: "tick"}).
+			AddTo(idle)
+		idle.Handler = &placeHandler{next: tick, buffer: &b, graphName: "loop", placeName: "idle"}
+		idle.AddTransition(tick)
+
+		g := graph.NewPetri[string, string]("loop", &graphHandler{buffer: &b, graphName: "loop"}).
+			SetStartPlace(idle).
+			SetFinishPlace(graph.NewPlace[string, string]("done", &placeHandler{buffer: &b, graphName: "loop", placeName: "done"}))
+
+		g.SetHistoryRetention(graph.HistoryRetention{MaxEntries: -1})
+
+		target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+			SetLogger(logger)
+
+		err := target.AddGraph(0, g)
+		if err != nil {
+			t.Fatalf("add graph: %v", err)
+		}
+
+		return testing.AllocsPerRun(100, func() {
+			_ = target.Act("tick")
+		})
+	}
+
+	off := slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+
+	without, disabled := allocs(nil), allocs(off)
+	if without != disabled {
+		t.Errorf("expected a disabled logger to allocate as much as none, got %v and %v", without, disabled)
+	}
+}
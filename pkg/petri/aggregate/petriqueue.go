@@ -1,8 +1,11 @@
 package aggregate
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
 	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
@@ -11,93 +14,400 @@ import (
 type PetriQueue[T any, V comparable] struct {
 	queue      *priority.Queue[T, V]
 	zeroSignal T
+	preemption PreemptionPolicy
+	offTurn    OffTurnPolicy
+	mailboxCap int
+	cascadeCap int
+	onError    ErrorPolicy
+	graphOnErr map[V]ErrorPolicy
+	after      func(time.Duration) <-chan time.Time
+	active     *graph.Petri[T, V]
+	intercept  []graph.Interceptor[T, V]
+	actHooks   []ActInterceptor[T, V]
+	logger     *slog.Logger
+	events     *graph.Bus[T, V]
 }
 
 func NewPetriQueue[T any, V comparable](q *priority.Queue[T, V], zero T) *PetriQueue[T, V] {
 	return &PetriQueue[T, V]{
 		queue:      q,
 		zeroSignal: zero,
+		after:      time.After,
 	}
 }
 
+func (p *PetriQueue[T, V]) SetPreemption(policy PreemptionPolicy) *PetriQueue[T, V] {
+	p.preemption = policy
+
+	return p
+}
+
+// Use adds interceptors around every handler call of every graph in the
+// queue. They run outside of the interceptors added to a graph itself.
+func (p *PetriQueue[T, V]) Use(interceptors ...graph.Interceptor[T, V]) *PetriQueue[T, V] {
+	p.intercept = append(p.intercept, interceptors...)
+
+	return p
+}
+
+// ActInterceptor wraps every ActReportContext call, including the ones made by
+// Act, ActContext and ActOn for the focused graph.
+type ActInterceptor[T any, V comparable] func(ctx context.Context, signal T, next func(context.Context) (*ActReport[V], error)) (*ActReport[V], error)
+
+// UseAct adds interceptors around every signal delivered to the queue. The
+// first added interceptor is the outermost one.
+func (p *PetriQueue[T, V]) UseAct(interceptors ...ActInterceptor[T, V]) *PetriQueue[T, V] {
+	p.actHooks = append(p.actHooks, interceptors...)
+
+	return p
+}
+
+func (p *PetriQueue[T, V]) context(ctx context.Context) context.Context {
+	if len(p.intercept) == 0 {
+		return ctx
+	}
+
+	return graph.WithInterceptors(ctx, p.intercept...)
+}
+
 func (p *PetriQueue[T, V]) AddGraph(level int, graph *graph.Petri[T, V]) error {
+	return p.AddGraphContext(context.Background(), level, graph)
+}
+
+func (p *PetriQueue[T, V]) AddGraphContext(ctx context.Context, level int, graph *graph.Petri[T, V]) error {
 	if p.queue == nil {
 		p.queue = priority.NewPriorityQueue[T, V]()
 	}
 
-	size := p.queue.Len()
-	currentLevel := p.queue.GetMaxPriority()
+	err := p.queue.Push(level, graph)
+	if err != nil {
+		return fmt.Errorf("add graph %v, level %d: %w", graph.ID, level, ErrDuplicateGraph)
+	}
+
+	p.emitAdded(graph, level)
 
-	p.queue.Push(level, graph)
+	err = p.startFocused(p.context(ctx))
+	if err != nil {
+		return fmt.Errorf("add graph and start: %w", err)
+	}
 
-	if graph.Current != nil {
-		return nil
+	return nil
+}
+
+func (p *PetriQueue[T, V]) FindGraph(id V) (*graph.Petri[T, V], int, bool) {
+	if p.queue == nil {
+		return nil, 0, false
 	}
 
-	if size != 0 && level <= currentLevel {
-		return nil
+	return p.queue.Find(id)
+}
+
+// History returns the audit trail of a queued or dead lettered graph.
+func (p *PetriQueue[T, V]) History(id V) ([]graph.HistoryEntry[T, V], bool) {
+	target, _, ok := p.FindGraph(id)
+	if ok {
+		return target.History, true
 	}
 
-	err := graph.StartGraph()
+	for _, dead := range p.DeadLetters() {
+		if dead.Graph.ID == id {
+			return dead.Graph.History, true
+		}
+	}
+
+	return nil, false
+}
+
+// ChangePriority moves a queued graph to another level. A graph that gets
+// focus is started the same way AddGraph does it.
+func (p *PetriQueue[T, V]) ChangePriority(id V, level int) error {
+	return p.ChangePriorityContext(context.Background(), id, level)
+}
+
+func (p *PetriQueue[T, V]) ChangePriorityContext(ctx context.Context, id V, level int) error {
+	if p.queue == nil || !p.queue.ChangePriority(id, level) {
+		return fmt.Errorf("graph %v: %w", id, ErrGraphNotFound)
+	}
+
+	err := p.startFocused(p.context(ctx))
 	if err != nil {
-		return fmt.Errorf("add graph and start: %w", err)
+		return fmt.Errorf("change priority of graph %v to %d and start: %w", id, level, err)
 	}
 
 	return nil
 }
 
-func (p *PetriQueue[T, V]) GetQueue() *priority.Queue[T, V] {
-	return p.queue
+// RemoveGraph deletes a graph from the queue. A started graph is cancelled
+// before it is returned, and a graph that gets focus is started the same way
+// AddGraph does it.
+func (p *PetriQueue[T, V]) RemoveGraph(id V) (*graph.Petri[T, V], error) {
+	return p.RemoveGraphContext(context.Background(), id)
 }
 
-func (p *PetriQueue[T, V]) Act(signal T) error {
+func (p *PetriQueue[T, V]) RemoveGraphContext(ctx context.Context, id V) (*graph.Petri[T, V], error) {
 	if p.queue == nil {
-		p.queue = priority.NewPriorityQueue[T, V]()
+		return nil, fmt.Errorf("graph %v: %w", id, ErrGraphNotFound)
 	}
 
-	if len(p.queue.GrQu) == 0 {
-		return nil
+	removed, ok := p.queue.Remove(id)
+	if !ok {
+		return nil, fmt.Errorf("graph %v: %w", id, ErrGraphNotFound)
 	}
 
-	current, priorityLevel, ok := p.queue.Peek()
-	if !ok {
-		return errors.New("unable to detect peek")
+	ctx = p.context(ctx)
+
+	err := removed.CancelGraphContext(ctx)
+	if err != nil {
+		return removed, fmt.Errorf("remove graph %v: %w", id, err)
 	}
 
-	err := current.Act(signal)
+	err = p.startFocused(ctx)
 	if err != nil {
-		return fmt.Errorf("unable to act priority %v, graph %v, signal %v: %w", priorityLevel, current, signal, err)
+		return removed, fmt.Errorf("remove graph %v and start next: %w", id, err)
 	}
 
-	if !current.IsOnFinish() {
-		return nil
+	return removed, nil
+}
+
+// AbortGraph compensates fired transitions of the graph in reverse order and
+// removes it from the queue. The graph stays queued if compensation fails. A
+// graph that gets focus is started the same way AddGraph does it.
+func (p *PetriQueue[T, V]) AbortGraph(id V) (*graph.Petri[T, V], error) {
+	return p.AbortGraphContext(context.Background(), id)
+}
+
+func (p *PetriQueue[T, V]) AbortGraphContext(ctx context.Context, id V) (*graph.Petri[T, V], error) {
+	target, level, ok := p.FindGraph(id)
+	if !ok {
+		return nil, fmt.Errorf("graph %v: %w", id, ErrGraphNotFound)
 	}
 
-	err = current.FinishGraph()
+	ctx = p.context(ctx)
+
+	err := target.AbortContext(p.graphContext(ctx, level))
 	if err != nil {
-		return fmt.Errorf("unable to finish graph %v, priority %v, signal %v: %w", current, priorityLevel, signal, err)
+		return target, fmt.Errorf("abort graph %v: %w", id, err)
 	}
 
-	graphToRemove, ok := p.queue.PopPriority(priorityLevel)
+	_, ok = p.queue.Remove(id)
 	if !ok {
-		return errors.New(fmt.Sprintf("unable to get graph to delete, level %d, signal %v", priorityLevel, signal))
+		return target, fmt.Errorf("graph %v: %w", id, ErrGraphNotFound)
 	}
 
-	if graphToRemove.ID != current.ID {
-		return errors.New(
-			fmt.Sprintf(
-				"ID of graphs are not identical, level %d, graph %v, to delete %v",
-				priorityLevel,
-				graphToRemove.ID,
-				current.ID,
-			),
-		)
+	err = p.startFocused(ctx)
+	if err != nil {
+		return target, fmt.Errorf("abort graph %v and start next: %w", id, err)
 	}
 
-	err = p.Act(p.zeroSignal)
+	return target, nil
+}
+
+func (p *PetriQueue[T, V]) startFocused(ctx context.Context) error {
+	current, level, ok, err := p.focus(ctx)
 	if err != nil {
-		return fmt.Errorf("act after current delete with zero signal: %w", err)
+		return err
 	}
 
-	return nil
+	if !ok || current.Current != nil {
+		return nil
+	}
+
+	return current.StartGraphContext(p.graphContext(ctx, level))
+}
+
+func (p *PetriQueue[T, V]) GetQueue() *priority.Queue[T, V] {
+	return p.queue
+}
+
+func (p *PetriQueue[T, V]) Act(signal T) error {
+	return p.ActContext(context.Background(), signal)
+}
+
+func (p *PetriQueue[T, V]) ActContext(ctx context.Context, signal T) error {
+	_, err := p.ActReportContext(ctx, signal)
+
+	return err
+}
+
+// ActReport delivers the signal like Act and reports the graphs completed on
+// the way. Each completed graph passes focus to the next one with the zero
+// signal, the chain of such deliveries is limited by SetMaxCascade.
+func (p *PetriQueue[T, V]) ActReport(signal T) (*ActReport[V], error) {
+	return p.ActReportContext(context.Background(), signal)
+}
+
+func (p *PetriQueue[T, V]) ActReportContext(ctx context.Context, signal T) (*ActReport[V], error) {
+	next := func(ctx context.Context) (*ActReport[V], error) {
+		return p.act(ctx, signal)
+	}
+
+	for i := len(p.actHooks) - 1; i >= 0; i-- {
+		hook, inner := p.actHooks[i], next
+		next = func(ctx context.Context) (*ActReport[V], error) {
+			return hook(ctx, signal, inner)
+		}
+	}
+
+	return next(ctx)
+}
+
+// delivery is a signal waiting in Act. Wake marks the zero signal passed to
+// the next graph once the focused one completes.
+type delivery[T any] struct {
+	signal T
+	wake   bool
+}
+
+func (p *PetriQueue[T, V]) act(ctx context.Context, signal T) (*ActReport[V], error) {
+	if p.queue == nil {
+		p.queue = priority.NewPriorityQueue[T, V]()
+	}
+
+	ctx = p.context(ctx)
+	report := &ActReport[V]{}
+	pending := []delivery[T]{{signal: signal}}
+
+	for len(pending) > 0 && len(p.queue.GrQu) != 0 {
+		next := pending[0]
+		pending = pending[1:]
+
+		current, priorityLevel, ok, err := p.focus(ctx)
+		if err != nil {
+			return report, fmt.Errorf("unable to focus graph: %w", err)
+		}
+
+		if !ok {
+			return report, ErrQueueEmpty
+		}
+
+		finished, buried, err := p.drain(ctx, current, priorityLevel)
+		if err != nil {
+			p.keep(current, append([]delivery[T]{next}, pending...))
+
+			return report, err
+		}
+
+		if finished || buried {
+			pending = append([]delivery[T]{next}, pending...)
+		} else {
+			finished, buried, err = p.deliver(ctx, current, priorityLevel, next.signal)
+			if next.wake && errors.Is(err, graph.ErrSignalIgnored) {
+				// the next graph waits for its own signal
+				continue
+			}
+
+			if err != nil {
+				return report, err
+			}
+		}
+
+		if !finished && !buried {
+			continue
+		}
+
+		if finished && len(current.Mailbox) > 0 {
+			if logger := p.logging(ctx, slog.LevelWarn); logger != nil {
+				logger.LogAttrs(ctx, slog.LevelWarn, "graph finished with buffered signals",
+					slog.Any("graph", current.ID), slog.Int("priority", priorityLevel), slog.Any("signals", current.Mailbox))
+			}
+		}
+
+		if buried {
+			report.DeadLettered = append(report.DeadLettered, current.ID)
+		} else {
+			report.Completed = append(report.Completed, current.ID)
+		}
+
+		if len(p.queue.GrQu) == 0 {
+			return report, nil
+		}
+
+		if report.Cascade >= p.maxCascade() {
+			if logger := p.logging(ctx, slog.LevelError); logger != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "cascade limit exceeded",
+					slog.Int("limit", p.maxCascade()), slog.Any("completed", report.Completed))
+			}
+
+			return report, &CascadeLimitError[V]{Limit: p.maxCascade(), Completed: report.Completed}
+		}
+
+		report.Cascade++
+		pending = append([]delivery[T]{{signal: p.zeroSignal, wake: true}}, pending...)
+	}
+
+	return report, nil
+}
+
+// drain delivers the buffered signals of the graph before anything else. Each
+// signal leaves the mailbox before delivery: an ignored one is dropped with a
+// warning, a failed one is reported in the error. The rest stays buffered when
+// the graph finishes or is dead lettered on the way.
+func (p *PetriQueue[T, V]) drain(ctx context.Context, current *graph.Petri[T, V], level int) (bool, bool, error) {
+	for len(current.Mailbox) > 0 {
+		signal := current.Mailbox[0]
+		current.Mailbox = current.Mailbox[1:]
+
+		finished, buried, err := p.deliver(ctx, current, level, signal)
+		if errors.Is(err, graph.ErrSignalIgnored) {
+			if logger := p.logging(ctx, slog.LevelWarn); logger != nil {
+				logger.LogAttrs(ctx, slog.LevelWarn, "buffered signal ignored",
+					slog.Any("graph", current.ID), slog.Int("priority", level), slog.Any("signal", signal))
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return false, false, fmt.Errorf("unable to deliver buffered signal %v to graph %v: %w", signal, current.ID, err)
+		}
+
+		if finished || buried {
+			return finished, buried, nil
+		}
+	}
+
+	return false, false, nil
+}
+
+// keep buffers the signals Act could not deliver after a buffered one failed,
+// so the graph gets them on the next Act. Wake signals are not kept.
+func (p *PetriQueue[T, V]) keep(current *graph.Petri[T, V], undelivered []delivery[T]) {
+	for _, d := range undelivered {
+		if !d.wake {
+			current.Mailbox = append(current.Mailbox, d.signal)
+		}
+	}
+}
+
+// actGraph delivers the signal to the graph and removes the graph from the
+// queue once it reaches its finish place.
+func (p *PetriQueue[T, V]) actGraph(ctx context.Context, current *graph.Petri[T, V], priorityLevel int, signal T) (bool, error) {
+	ctx = p.graphContext(ctx, priorityLevel)
+
+	err := current.ActContext(ctx, signal)
+	if err != nil {
+		return false, fmt.Errorf("unable to act priority %v, graph %v, signal %v: %w", priorityLevel, current.ID, signal, err)
+	}
+
+	if !current.IsOnFinish() {
+		return false, nil
+	}
+
+	return p.finishGraph(ctx, current, priorityLevel, signal)
+}
+
+// finishGraph runs the finish stage of a graph standing on its finish place
+// and removes the graph from the queue.
+func (p *PetriQueue[T, V]) finishGraph(ctx context.Context, current *graph.Petri[T, V], priorityLevel int, signal T) (bool, error) {
+	err := current.FinishGraphContext(ctx)
+	if err != nil {
+		return false, fmt.Errorf("unable to finish graph %v, priority %v, signal %v: %w", current.ID, priorityLevel, signal, err)
+	}
+
+	_, ok := p.queue.Remove(current.ID)
+	if !ok {
+		return false, fmt.Errorf("unable to get graph %v to delete, level %d, signal %v: %w", current.ID, priorityLevel, signal, ErrGraphNotFound)
+	}
+
+	return true, nil
 }
@@ -1,6 +1,8 @@
 package aggregate_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"testing"
@@ -275,3 +277,294 @@ func makeGraph2(b *buffer) *graph.Petri[string, string] {
 		SetStartPlace(placeStart).
 		SetFinishPlace(placeFinish)
 }
+
+func TestPetriQueue_RemoveGraph(t *testing.T) {
+	b := buffer{current: "\n"}
+	expected := `
+handle in graph, graph graph1
+handle in to start from NIL, graph graph1
+handle out from start to NIL, graph graph1
+handle out graph graph1
+handle in graph, graph graph2
+handle in to start from NIL, graph graph2
+ChooseTo at start, signal sig, graph graph2
+signal sig, transition start_to_finish, graph graph2
+handle out from start to middle, graph graph2
+handle in to middle from start, graph graph2
+`
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	err := target.AddGraph(0, makeGraph1(&b))
+	if err != nil {
+		t.Errorf("failed to add first graph")
+	}
+
+	err = target.AddGraph(0, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add second graph")
+	}
+
+	_, level, ok := target.FindGraph("graph2")
+	if !ok || level != 0 {
+		t.Errorf("graph2 not found on level 0")
+	}
+
+	removed, err := target.RemoveGraph("graph1")
+	if err != nil {
+		t.Errorf("failed to remove graph: %v", err)
+	}
+
+	if removed.ID != "graph1" {
+		t.Errorf("removed wrong graph %v", removed.ID)
+	}
+
+	next, _, _ := target.FindGraph("graph2")
+	if next.Current == nil {
+		t.Errorf("graph2 expected to be started once it got focus")
+	}
+
+	_, err = target.RemoveGraph("graph1")
+	if err == nil {
+		t.Errorf("expected error for removed graph")
+	}
+
+	err = target.Act("sig")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	if expected != b.Result() {
+		t.Errorf("expected: %s, got: %s", expected, b.Result())
+	}
+}
+
+func TestPetriQueue_ChangePriority(t *testing.T) {
+	b := buffer{current: "\n"}
+	expected := `
+handle in graph, graph graph1
+handle in to start from NIL, graph graph1
+handle in graph, graph graph2
+handle in to start from NIL, graph graph2
+`
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	err := target.AddGraph(0, makeGraph1(&b))
+	if err != nil {
+		t.Errorf("failed to add first graph")
+	}
+
+	err = target.AddGraph(0, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add second graph")
+	}
+
+	err = target.ChangePriority("graph2", 3)
+	if err != nil {
+		t.Errorf("change priority: %v", err)
+	}
+
+	err = target.ChangePriority("unknown", 3)
+	if err == nil {
+		t.Errorf("expected error for unknown graph")
+	}
+
+	if expected != b.Result() {
+		t.Errorf("expected: %s, got: %s", expected, b.Result())
+	}
+}
+
+func TestPetriQueue_AbortGraph(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	err := target.AddGraph(0, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add graph")
+	}
+
+	err = target.AddGraph(0, makeGraph1(&b))
+	if err != nil {
+		t.Errorf("failed to add second graph")
+	}
+
+	err = target.Act("sig")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	aborted, err := target.AbortGraph("graph2")
+	if err != nil {
+		t.Errorf("abort: %v", err)
+	}
+
+	if len(aborted.Fired) != 0 || aborted.Current != nil {
+		t.Errorf("expected aborted graph to be reset, got %+v", aborted)
+	}
+
+	if _, _, ok := target.FindGraph("graph2"); ok {
+		t.Errorf("aborted graph expected to be removed")
+	}
+
+	next, _, _ := target.FindGraph("graph1")
+	if next.Current == nil {
+		t.Errorf("graph1 expected to be started once it got focus")
+	}
+
+	_, err = target.AbortGraph("graph2")
+	if err == nil {
+		t.Errorf("expected error for removed graph")
+	}
+}
+
+func TestPetriQueue_AbortGraphContext_Cancelled(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	err := target.AddGraph(0, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add graph")
+	}
+
+	err = target.Act("sig")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = target.AbortGraphContext(ctx, "graph2")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if _, _, ok := target.FindGraph("graph2"); !ok {
+		t.Errorf("graph2 expected to stay in queue")
+	}
+}
+
+func TestPetriQueue_ActContext_Cancelled(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	err := target.AddGraph(0, makeGraph1(&b))
+	if err != nil {
+		t.Errorf("failed to add graph")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = target.ActContext(ctx, "sig")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	if _, _, ok := target.FindGraph("graph1"); !ok {
+		t.Errorf("graph1 expected to stay in queue")
+	}
+}
+
+func TestPetriQueue_History(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	err := target.AddGraph(0, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add graph")
+	}
+
+	err = target.Act("sig")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	history, ok := target.History("graph2")
+	if !ok || len(history) != 1 || history[0].Transition != "start_to_finish" {
+		t.Errorf("unexpected history %+v", history)
+	}
+
+	if _, ok = target.History("unknown"); ok {
+		t.Errorf("expected no history for unknown graph")
+	}
+}
+
+func TestPetriQueue_Use(t *testing.T) {
+	b := buffer{current: "\n"}
+	stages := make(map[graph.Stage]int)
+
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+		Use(func(ctx context.Context, call graph.Call[string, string], next func(context.Context) error) error {
+			stages[call.Stage]++
+
+			return next(ctx)
+		})
+
+	err := target.AddGraph(0, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add graph")
+	}
+
+	for _, signal := range []string{"sig0", "sig1"} {
+		err = target.Act(signal)
+		if err != nil {
+			t.Errorf("act: %v", err)
+		}
+	}
+
+	expected := map[graph.Stage]int{
+		graph.StageGraphHandleIn:  1,
+		graph.StagePlaceHandleIn:  3,
+		graph.StageChooseTo:       2,
+		graph.StageHandle:         2,
+		graph.StagePlaceHandleOut: 3,
+		graph.StageGraphHandleOut: 1,
+	}
+
+	for stage, count := range expected {
+		if stages[stage] != count {
+			t.Errorf("stage %v: expected %d calls, got %d", stage, count, stages[stage])
+		}
+	}
+}
+
+func TestPetriQueue_Errors(t *testing.T) {
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	_, err := target.RemoveGraph("unknown")
+	if !errors.Is(err, aggregate.ErrGraphNotFound) {
+		t.Errorf("expected ErrGraphNotFound, got %v", err)
+	}
+
+	err = target.ActOn("unknown", "sig")
+	if !errors.Is(err, aggregate.ErrGraphNotFound) {
+		t.Errorf("expected ErrGraphNotFound, got %v", err)
+	}
+
+	err = target.Requeue("unknown")
+	if !errors.Is(err, aggregate.ErrGraphNotFound) {
+		t.Errorf("expected ErrGraphNotFound, got %v", err)
+	}
+}
+
+func TestPetriQueue_AddGraph_Duplicate(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	first := makeGraph1(&b)
+
+	err := target.AddGraph(0, first)
+	if err != nil {
+		t.Fatalf("failed to add first graph: %v", err)
+	}
+
+	err = target.AddGraph(1, makeGraph1(&b))
+	if !errors.Is(err, aggregate.ErrDuplicateGraph) {
+		t.Errorf("expected ErrDuplicateGraph, got %v", err)
+	}
+
+	found, level, ok := target.FindGraph("graph1")
+	if !ok || found != first || level != 0 {
+		t.Errorf("expected the first graph to stay on level 0, got %v on level %d", found, level)
+	}
+}
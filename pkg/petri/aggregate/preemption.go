@@ -0,0 +1,125 @@
+package aggregate
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// PreemptionPolicy decides whether a started graph gives up focus when a
+// graph with a higher priority appears in the queue.
+type PreemptionPolicy int
+
+const (
+	// PreemptAlways switches to the higher priority graph immediately.
+	PreemptAlways PreemptionPolicy = iota
+	// PreemptNever lets the started graph run until it finishes.
+	PreemptNever
+	// PreemptAtSafePlaces switches only while the started graph is on a place marked as Safe.
+	PreemptAtSafePlaces
+)
+
+func (p PreemptionPolicy) String() string {
+	switch p {
+	case PreemptAlways:
+		return "always"
+	case PreemptNever:
+		return "never"
+	case PreemptAtSafePlaces:
+		return "safe places"
+	default:
+		return fmt.Sprintf("PreemptionPolicy(%d)", int(p))
+	}
+}
+
+// focus returns the graph that receives the next signal. It switches from the
+// active graph to the head of the queue when the preemption policy allows it,
+// suspending the former and resuming the latter.
+func (p *PetriQueue[T, V]) focus(ctx context.Context) (*graph.Petri[T, V], int, bool, error) {
+	head, headLevel, ok := p.queue.Peek()
+	if !ok {
+		return nil, 0, false, nil
+	}
+
+	active, activeLevel, ok := p.activeGraph()
+	if ok && active != head && !p.preemptible(active) {
+		return active, activeLevel, true, nil
+	}
+
+	if ok && active != head {
+		err := active.SuspendContext(p.graphContext(ctx, activeLevel))
+		if err != nil {
+			return nil, 0, false, fmt.Errorf("preempting graph %v on level %d: %w", active.ID, activeLevel, err)
+		}
+
+		if logger := p.logging(ctx, slog.LevelInfo); logger != nil {
+			logger.LogAttrs(ctx, slog.LevelInfo, "graph preempted",
+				slog.Any("graph", active.ID), slog.Int("priority", activeLevel),
+				slog.Any("by", head.ID), slog.Int("by_priority", headLevel))
+		}
+
+		p.emitPreempted(active, activeLevel, head)
+	}
+
+	p.active = head
+
+	err := head.ResumeContext(p.graphContext(ctx, headLevel))
+	if err != nil {
+		return nil, 0, false, fmt.Errorf("resuming graph %v on level %d: %w", head.ID, headLevel, err)
+	}
+
+	return head, headLevel, true, nil
+}
+
+// activeGraph returns the graph that had focus last time. After the state is
+// loaded from storage it is the started graph that is not suspended.
+func (p *PetriQueue[T, V]) activeGraph() (*graph.Petri[T, V], int, bool) {
+	if p.active != nil {
+		found, level, ok := p.queue.Find(p.active.ID)
+		if ok && found == p.active {
+			return found, level, true
+		}
+
+		p.active = nil
+	}
+
+	var (
+		result *graph.Petri[T, V]
+		level  int
+	)
+
+	p.queue.Range(func(l int, g *graph.Petri[T, V]) bool {
+		if g.Current == nil || g.Suspended {
+			return true
+		}
+
+		result, level = g, l
+
+		return false
+	})
+
+	if result == nil {
+		return nil, 0, false
+	}
+
+	p.active = result
+
+	return result, level, true
+}
+
+func (p *PetriQueue[T, V]) preemptible(active *graph.Petri[T, V]) bool {
+	if active.Current == nil {
+		return true
+	}
+
+	switch p.preemption {
+	case PreemptNever:
+		return false
+	case PreemptAtSafePlaces:
+		return active.Current.Safe
+	default:
+		return true
+	}
+}
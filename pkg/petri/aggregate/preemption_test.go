@@ -0,0 +1,178 @@
+package aggregate_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+type suspendingGraphHandler struct {
+	graphHandler
+}
+
+func (h *suspendingGraphHandler) Suspend() error {
+	h.buffer.Add(fmt.Sprintf("suspend graph %s", h.graphName))
+
+	return nil
+}
+
+func (h *suspendingGraphHandler) Resume() error {
+	h.buffer.Add(fmt.Sprintf("resume graph %s", h.graphName))
+
+	return nil
+}
+
+func TestPetriQueue_Preemption_Always(t *testing.T) {
+	b := buffer{current: "\n"}
+	expected := `
+handle in graph, graph graph1
+handle in to start from NIL, graph graph1
+suspend graph graph1
+handle in graph, graph graph2
+handle in to start from NIL, graph graph2
+ChooseTo at start, signal sig0, graph graph2
+signal sig0, transition start_to_finish, graph graph2
+handle out from start to middle, graph graph2
+handle in to middle from start, graph graph2
+ChooseTo at middle, signal sig1, graph graph2
+signal sig1, transition middle_to_finish, graph graph2
+handle out from middle to finish, graph graph2
+handle in to finish from middle, graph graph2
+handle out from finish to NIL, graph graph2
+handle out graph graph2
+resume graph graph1
+ChooseTo at start, signal 0, graph graph1
+signal 0, transition middle, graph graph1
+handle out from start to finish, graph graph1
+handle in to finish from start, graph graph1
+handle out from finish to NIL, graph graph1
+handle out graph graph1
+`
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+
+	graph1 := makeGraph1(&b)
+	graph1.Handler = &suspendingGraphHandler{graphHandler{buffer: &b, graphName: "graph1"}}
+
+	err := target.AddGraph(0, graph1)
+	if err != nil {
+		t.Errorf("failed to add first graph")
+	}
+
+	err = target.AddGraph(1, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add second graph")
+	}
+
+	for _, signal := range []string{"sig0", "sig1"} {
+		err = target.Act(signal)
+		if err != nil {
+			t.Errorf("signal %s, %v", signal, err)
+		}
+	}
+
+	if expected != b.Result() {
+		t.Errorf("expected: %s, got: %s", expected, b.Result())
+	}
+}
+
+func TestPetriQueue_Preemption_Never(t *testing.T) {
+	b := buffer{current: "\n"}
+	expected := `
+handle in graph, graph graph1
+handle in to start from NIL, graph graph1
+ChooseTo at start, signal sig0, graph graph1
+signal sig0, transition middle, graph graph1
+handle out from start to finish, graph graph1
+handle in to finish from start, graph graph1
+handle out from finish to NIL, graph graph1
+handle out graph graph1
+handle in graph, graph graph2
+handle in to start from NIL, graph graph2
+ChooseTo at start, signal 0, graph graph2
+signal 0, transition start_to_finish, graph graph2
+handle out from start to middle, graph graph2
+handle in to middle from start, graph graph2
+`
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+		SetPreemption(aggregate.PreemptNever)
+
+	err := target.AddGraph(0, makeGraph1(&b))
+	if err != nil {
+		t.Errorf("failed to add first graph")
+	}
+
+	err = target.AddGraph(1, makeGraph2(&b))
+	if err != nil {
+		t.Errorf("failed to add second graph")
+	}
+
+	err = target.Act("sig0")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	if expected != b.Result() {
+		t.Errorf("expected: %s, got: %s", expected, b.Result())
+	}
+}
+
+func TestPetriQueue_Preemption_AtSafePlaces(t *testing.T) {
+	b := buffer{current: "\n"}
+	expected := `
+handle in graph, graph graph2
+handle in to start from NIL, graph graph2
+ChooseTo at start, signal sig0, graph graph2
+signal sig0, transition start_to_finish, graph graph2
+handle out from start to middle, graph graph2
+handle in to middle from start, graph graph2
+suspend graph graph2
+handle in graph, graph graph1
+handle in to start from NIL, graph graph1
+ChooseTo at start, signal sig1, graph graph1
+signal sig1, transition middle, graph graph1
+handle out from start to finish, graph graph1
+handle in to finish from start, graph graph1
+handle out from finish to NIL, graph graph1
+handle out graph graph1
+resume graph graph2
+ChooseTo at middle, signal 0, graph graph2
+signal 0, transition middle_to_finish, graph graph2
+handle out from middle to finish, graph graph2
+handle in to finish from middle, graph graph2
+handle out from finish to NIL, graph graph2
+handle out graph graph2
+`
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+		SetPreemption(aggregate.PreemptAtSafePlaces)
+
+	graph2 := makeGraph2(&b)
+	graph2.Handler = &suspendingGraphHandler{graphHandler{buffer: &b, graphName: "graph2"}}
+
+	err := target.AddGraph(0, graph2)
+	if err != nil {
+		t.Errorf("failed to add first graph")
+	}
+
+	err = target.AddGraph(1, makeGraph1(&b))
+	if err != nil {
+		t.Errorf("failed to add second graph")
+	}
+
+	err = target.Act("sig0")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	graph2.Current.SetSafe(true)
+
+	err = target.Act("sig1")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	if expected != b.Result() {
+		t.Errorf("expected: %s, got: %s", expected, b.Result())
+	}
+}
@@ -0,0 +1,135 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// OffTurnPolicy decides what ActOn does with a signal for a graph that does
+// not have focus.
+type OffTurnPolicy int
+
+const (
+	// OffTurnReject returns ErrGraphNotActive.
+	OffTurnReject OffTurnPolicy = iota
+	// OffTurnBuffer keeps the signal until the graph gets focus.
+	OffTurnBuffer
+	// OffTurnRun delivers the signal immediately without changing focus.
+	OffTurnRun
+)
+
+func (p OffTurnPolicy) String() string {
+	switch p {
+	case OffTurnReject:
+		return "reject"
+	case OffTurnBuffer:
+		return "buffer"
+	case OffTurnRun:
+		return "run"
+	default:
+		return fmt.Sprintf("OffTurnPolicy(%d)", int(p))
+	}
+}
+
+func (p *PetriQueue[T, V]) SetOffTurnPolicy(policy OffTurnPolicy) *PetriQueue[T, V] {
+	p.offTurn = policy
+
+	return p
+}
+
+// SetMailboxLimit caps the number of buffered signals per graph, zero means no limit.
+func (p *PetriQueue[T, V]) SetMailboxLimit(limit int) *PetriQueue[T, V] {
+	p.mailboxCap = limit
+
+	return p
+}
+
+// ActOn delivers the signal to the graph with the given ID regardless of its
+// position in the queue.
+func (p *PetriQueue[T, V]) ActOn(id V, signal T) error {
+	return p.ActOnContext(context.Background(), id, signal)
+}
+
+func (p *PetriQueue[T, V]) ActOnContext(ctx context.Context, id V, signal T) error {
+	target, level, ok := p.FindGraph(id)
+	if !ok {
+		return fmt.Errorf("graph %v: %w", id, ErrGraphNotFound)
+	}
+
+	current, _, ok, err := p.focus(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to focus graph: %w", err)
+	}
+
+	if ok && current == target {
+		return p.ActContext(ctx, signal)
+	}
+
+	switch p.offTurn {
+	case OffTurnBuffer:
+		return p.buffer(target, signal)
+	case OffTurnRun:
+		_, _, err = p.actOffTurn(p.context(ctx), target, level, signal)
+
+		return err
+	default:
+		return fmt.Errorf("graph %v on level %d, signal %v: %w", id, level, signal, ErrGraphNotActive)
+	}
+}
+
+// actOffTurn runs a graph without focus under the error policy. The graph is
+// resumed once its place chose a transition for the signal, and suspended
+// afterwards unless it finishes, so a graph ignoring the signal sees neither
+// hook.
+func (p *PetriQueue[T, V]) actOffTurn(ctx context.Context, target *graph.Petri[T, V], level int, signal T) (bool, bool, error) {
+	graphCtx := p.graphContext(ctx, level)
+
+	resumed := false
+	resume := func(ctx context.Context, call graph.Call[T, V], next func(context.Context) error) error {
+		if resumed || call.GraphID != target.ID || call.Stage == graph.StageChooseTo {
+			return next(ctx)
+		}
+
+		resumed = true
+
+		err := target.ResumeContext(ctx)
+		if err != nil {
+			return fmt.Errorf("resuming graph %v out of turn: %w", target.ID, err)
+		}
+
+		return next(ctx)
+	}
+
+	finished, buried, err := p.deliver(graph.WithInterceptors(ctx, resume), target, level, signal)
+	if finished {
+		return true, false, nil
+	}
+
+	if err != nil {
+		err = fmt.Errorf("out of turn: %w", err)
+	}
+
+	if !resumed {
+		return false, buried, err
+	}
+
+	suspendErr := target.SuspendContext(graphCtx)
+	if suspendErr != nil {
+		suspendErr = fmt.Errorf("suspending graph %v after out of turn signal: %w", target.ID, suspendErr)
+	}
+
+	return false, buried, errors.Join(err, suspendErr)
+}
+
+func (p *PetriQueue[T, V]) buffer(target *graph.Petri[T, V], signal T) error {
+	if p.mailboxCap > 0 && len(target.Mailbox) >= p.mailboxCap {
+		return fmt.Errorf("graph %v, limit %d, signal %v: %w", target.ID, p.mailboxCap, signal, ErrMailboxFull)
+	}
+
+	target.Mailbox = append(target.Mailbox, signal)
+
+	return nil
+}
@@ -0,0 +1,303 @@
+package aggregate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+func newOneLevelQueue(t *testing.T, b *buffer, policy aggregate.OffTurnPolicy) *aggregate.PetriQueue[string, string] {
+	t.Helper()
+
+	target := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+		SetOffTurnPolicy(policy)
+
+	err := target.AddGraph(0, makeGraph1(b))
+	if err != nil {
+		t.Fatalf("failed to add first graph")
+	}
+
+	err = target.AddGraph(0, makeGraph2(b))
+	if err != nil {
+		t.Fatalf("failed to add second graph")
+	}
+
+	return target
+}
+
+func TestPetriQueue_ActOn_Reject(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnReject)
+
+	err := target.ActOn("graph2", "sig")
+	if !errors.Is(err, aggregate.ErrGraphNotActive) {
+		t.Errorf("expected ErrGraphNotActive, got %v", err)
+	}
+
+	err = target.ActOn("unknown", "sig")
+	if err == nil {
+		t.Errorf("expected error for unknown graph")
+	}
+
+	err = target.ActOn("graph1", "sig")
+	if err != nil {
+		t.Errorf("act on active graph: %v", err)
+	}
+
+	if _, _, ok := target.FindGraph("graph1"); ok {
+		t.Errorf("graph1 expected to be finished")
+	}
+}
+
+func TestPetriQueue_ActOn_Buffer(t *testing.T) {
+	b := buffer{current: "\n"}
+	expected := `
+handle in graph, graph graph1
+handle in to start from NIL, graph graph1
+ChooseTo at start, signal sig0, graph graph1
+signal sig0, transition middle, graph graph1
+handle out from start to finish, graph graph1
+handle in to finish from start, graph graph1
+handle out from finish to NIL, graph graph1
+handle out graph graph1
+handle in graph, graph graph2
+handle in to start from NIL, graph graph2
+ChooseTo at start, signal buffered, graph graph2
+signal buffered, transition start_to_finish, graph graph2
+handle out from start to middle, graph graph2
+handle in to middle from start, graph graph2
+ChooseTo at middle, signal 0, graph graph2
+signal 0, transition middle_to_finish, graph graph2
+handle out from middle to finish, graph graph2
+handle in to finish from middle, graph graph2
+handle out from finish to NIL, graph graph2
+handle out graph graph2
+`
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnBuffer)
+
+	err := target.ActOn("graph2", "buffered")
+	if err != nil {
+		t.Errorf("act on graph2: %v", err)
+	}
+
+	err = target.Act("sig0")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	if expected != b.Result() {
+		t.Errorf("expected: %s, got: %s", expected, b.Result())
+	}
+}
+
+func TestPetriQueue_ActOn_Run(t *testing.T) {
+	b := buffer{current: "\n"}
+	expected := `
+handle in graph, graph graph1
+handle in to start from NIL, graph graph1
+handle in graph, graph graph2
+handle in to start from NIL, graph graph2
+ChooseTo at start, signal out, graph graph2
+signal out, transition start_to_finish, graph graph2
+handle out from start to middle, graph graph2
+handle in to middle from start, graph graph2
+ChooseTo at start, signal sig0, graph graph1
+signal sig0, transition middle, graph graph1
+handle out from start to finish, graph graph1
+handle in to finish from start, graph graph1
+handle out from finish to NIL, graph graph1
+handle out graph graph1
+ChooseTo at middle, signal 0, graph graph2
+signal 0, transition middle_to_finish, graph graph2
+handle out from middle to finish, graph graph2
+handle in to finish from middle, graph graph2
+handle out from finish to NIL, graph graph2
+handle out graph graph2
+`
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnRun)
+
+	err := target.ActOn("graph2", "out")
+	if err != nil {
+		t.Errorf("act on graph2: %v", err)
+	}
+
+	err = target.Act("sig0")
+	if err != nil {
+		t.Errorf("act: %v", err)
+	}
+
+	if expected != b.Result() {
+		t.Errorf("expected: %s, got: %s", expected, b.Result())
+	}
+}
+
+func TestPetriQueue_ActOn_RunHooks(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnRun)
+
+	graph2, _, _ := target.FindGraph("graph2")
+	graph2.Handler = &suspendingGraphHandler{graphHandler{buffer: &b, graphName: "graph2"}}
+
+	err := target.ActOn("graph2", "out")
+	if err != nil {
+		t.Errorf("act on graph2: %v", err)
+	}
+
+	b.current = "\n"
+	expected := `
+ChooseTo at middle, signal sig1, graph graph2
+resume graph graph2
+signal sig1, transition middle_to_finish, graph graph2
+handle out from middle to finish, graph graph2
+handle in to finish from middle, graph graph2
+handle out from finish to NIL, graph graph2
+handle out graph graph2
+`
+
+	err = target.ActOn("graph2", "sig1")
+	if err != nil {
+		t.Errorf("act on graph2: %v", err)
+	}
+
+	if expected != b.Result() {
+		t.Errorf("expected: %s, got: %s", expected, b.Result())
+	}
+}
+
+func TestPetriQueue_ActOn_MailboxLimit(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnBuffer).SetMailboxLimit(2)
+
+	for i := 0; i < 2; i++ {
+		err := target.ActOn("graph2", "buffered")
+		if err != nil {
+			t.Errorf("act on graph2: %v", err)
+		}
+	}
+
+	err := target.ActOn("graph2", "buffered")
+	if !errors.Is(err, aggregate.ErrMailboxFull) {
+		t.Errorf("expected ErrMailboxFull, got %v", err)
+	}
+
+	graph2, _, _ := target.FindGraph("graph2")
+	if len(graph2.Mailbox) != 2 {
+		t.Errorf("expected 2 buffered signals, got %v", graph2.Mailbox)
+	}
+}
+
+func TestPetriQueue_ActOn_BufferFailure(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnBuffer)
+
+	graph2, _, _ := target.FindGraph("graph2")
+	graph2.Start.Handler = &failingPlaceHandler{placeHandler: *graph2.Start.Handler.(*placeHandler), fails: 1}
+
+	for _, signal := range []string{"b1", "b2"} {
+		err := target.ActOn("graph2", signal)
+		if err != nil {
+			t.Fatalf("act on graph2: %v", err)
+		}
+	}
+
+	_, err := target.RemoveGraph("graph1")
+	if err != nil {
+		t.Fatalf("remove graph1: %v", err)
+	}
+
+	err = target.Act("sig")
+	if err == nil {
+		t.Fatalf("expected buffered signal to fail")
+	}
+
+	if len(graph2.Mailbox) != 2 || graph2.Mailbox[0] != "b2" || graph2.Mailbox[1] != "sig" {
+		t.Errorf("expected failed signal dropped and the rest buffered, got %v", graph2.Mailbox)
+	}
+
+	report, err := target.ActReport("0")
+	if err != nil {
+		t.Fatalf("redeliver buffered signals: %v", err)
+	}
+
+	if len(report.Completed) != 1 || report.Completed[0] != "graph2" {
+		t.Errorf("expected graph2 to complete, got %v", report.Completed)
+	}
+
+	if len(graph2.Mailbox) != 0 {
+		t.Errorf("expected empty mailbox, got %v", graph2.Mailbox)
+	}
+}
+
+type selectivePlaceHandler struct {
+	placeHandler
+	ignore string
+}
+
+func (h *selectivePlaceHandler) ChooseTo(signal string) (*graph.Transition[string, string], error) {
+	if signal == h.ignore {
+		return nil, graph.ErrSignalIgnored
+	}
+
+	return h.placeHandler.ChooseTo(signal)
+}
+
+func TestPetriQueue_ActOn_BufferIgnored(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnBuffer)
+
+	graph2, _, _ := target.FindGraph("graph2")
+	graph2.Start.Handler = &selectivePlaceHandler{placeHandler: *graph2.Start.Handler.(*placeHandler), ignore: "skip"}
+
+	err := target.ActOn("graph2", "skip")
+	if err != nil {
+		t.Fatalf("act on graph2: %v", err)
+	}
+
+	err = target.Act("sig")
+	if err != nil {
+		t.Fatalf("expected ignored buffered signal to be dropped, got %v", err)
+	}
+
+	if len(graph2.Mailbox) != 0 {
+		t.Errorf("expected empty mailbox, got %v", graph2.Mailbox)
+	}
+
+	report, err := target.ActReport("sig")
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+
+	if len(report.Completed) != 1 || report.Completed[0] != "graph2" {
+		t.Errorf("expected graph2 to complete, got %v", report.Completed)
+	}
+}
+
+func TestPetriQueue_ActOn_BufferFinishes(t *testing.T) {
+	b := buffer{current: "\n"}
+	target := newOneLevelQueue(t, &b, aggregate.OffTurnBuffer)
+
+	graph2, _, _ := target.FindGraph("graph2")
+
+	for _, signal := range []string{"b1", "b2", "b3"} {
+		err := target.ActOn("graph2", signal)
+		if err != nil {
+			t.Fatalf("act on graph2: %v", err)
+		}
+	}
+
+	report, err := target.ActReport("sig")
+	if err != nil {
+		t.Fatalf("act: %v", err)
+	}
+
+	if len(report.Completed) != 2 {
+		t.Errorf("expected both graphs to complete, got %v", report.Completed)
+	}
+
+	if len(graph2.Mailbox) != 1 || graph2.Mailbox[0] != "b3" {
+		t.Errorf("expected undelivered signal to stay with the finished graph, got %v", graph2.Mailbox)
+	}
+}
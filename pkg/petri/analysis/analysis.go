@@ -0,0 +1,336 @@
+// Package analysis checks the structure of nets described by a
+// registry.Net.
+//
+// Reachability and deadlocks follow the semantics of a graph: a single token
+// moves from a place along one of its transitions to one of the transition
+// places. Boundedness follows the place/transition net semantics, where a
+// transition consumes a token from each input place and produces one on each
+// output place; it tells whether the net is safe to run as a graph at all.
+package analysis
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/uzh13/GuePetri/pkg/petri/registry"
+)
+
+// DefaultLimit caps the nodes of the coverability tree built by Analyze.
+const DefaultLimit = 10000
+
+// Report is the result of Analyze. Node lists keep the order of the net.
+type Report struct {
+	// Unreachable lists places and transitions the token never gets to from
+	// the start place
+	Unreachable []string
+	// FinishReachable tells whether the finish place may be reached
+	FinishReachable bool
+	// DeadEnds lists reachable places other than finish without transitions
+	DeadEnds []string
+	// Traps lists reachable places from which the finish place can no longer
+	// be reached, dead ends excluded
+	Traps []string
+	// Bound is the maximum number of tokens a place may hold, it is -1 when
+	// the net is unbounded or the bound is unknown
+	Bound int
+	// Unbounded lists places that may hold any number of tokens
+	Unbounded []string
+	// Incomplete is set when the coverability tree exceeded the limit
+	Incomplete bool
+}
+
+// OK tells whether the net runs as a graph that always can be finished.
+func (r Report) OK() bool {
+	return len(r.Unreachable) == 0 &&
+		r.FinishReachable &&
+		len(r.DeadEnds) == 0 &&
+		len(r.Traps) == 0 &&
+		r.Bound == 1
+}
+
+// Write prints the report in a human readable form.
+func (r Report) Write(w io.Writer) error {
+	b := strings.Builder{}
+
+	line := func(name string, nodes []string) {
+		value := "none"
+		if len(nodes) > 0 {
+			value = strings.Join(nodes, ", ")
+		}
+
+		fmt.Fprintf(&b, "%-12s %s\n", name+":", value)
+	}
+
+	line("unreachable", r.Unreachable)
+	fmt.Fprintf(&b, "%-12s %t\n", "finish:", r.FinishReachable)
+	line("dead ends", r.DeadEnds)
+	line("traps", r.Traps)
+
+	switch {
+	case r.Incomplete:
+		fmt.Fprintf(&b, "%-12s unknown, state space exceeds the limit\n", "bound:")
+	case r.Bound < 0:
+		fmt.Fprintf(&b, "%-12s unbounded on %s\n", "bound:", strings.Join(r.Unbounded, ", "))
+	default:
+		fmt.Fprintf(&b, "%-12s %d\n", "bound:", r.Bound)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// Analyze checks the net with the default limit.
+func Analyze(n registry.Net) Report {
+	return AnalyzeLimit(n, DefaultLimit)
+}
+
+// AnalyzeLimit checks the net building at most limit nodes of the
+// coverability tree.
+func AnalyzeLimit(n registry.Net, limit int) Report {
+	s := newStructure(n)
+	r := Report{}
+
+	reached := s.reachable(n.Start)
+	for _, node := range n.Places {
+		if _, ok := reached[node.ID]; !ok {
+			r.Unreachable = append(r.Unreachable, node.ID)
+		}
+	}
+
+	for _, node := range n.Transitions {
+		if _, ok := reached[node.ID]; !ok {
+			r.Unreachable = append(r.Unreachable, node.ID)
+		}
+	}
+
+	_, r.FinishReachable = reached[n.Finish]
+
+	finishing := s.reaching(n.Finish)
+	for _, node := range n.Places {
+		if _, ok := reached[node.ID]; !ok || node.ID == n.Finish {
+			continue
+		}
+
+		switch {
+		case len(s.out[node.ID]) == 0:
+			r.DeadEnds = append(r.DeadEnds, node.ID)
+		case !contains(finishing, node.ID):
+			r.Traps = append(r.Traps, node.ID)
+		}
+	}
+
+	r.Bound, r.Unbounded, r.Incomplete = s.boundedness(n, limit)
+
+	return r
+}
+
+// structure keeps arcs of the net by node.
+type structure struct {
+	places []string
+	index  map[string]int
+	out    map[string][]string
+	in     map[string][]string
+	pre    map[string][]int
+	post   map[string][]int
+	order  []string
+}
+
+func newStructure(n registry.Net) structure {
+	s := structure{
+		index: map[string]int{},
+		out:   map[string][]string{},
+		in:    map[string][]string{},
+		pre:   map[string][]int{},
+		post:  map[string][]int{},
+	}
+
+	for i, node := range n.Places {
+		s.places = append(s.places, node.ID)
+		s.index[node.ID] = i
+	}
+
+	for _, node := range n.Transitions {
+		s.order = append(s.order, node.ID)
+	}
+
+	for _, a := range n.Arcs {
+		s.out[a.From] = append(s.out[a.From], a.To)
+		s.in[a.To] = append(s.in[a.To], a.From)
+
+		if i, ok := s.index[a.From]; ok {
+			s.pre[a.To] = append(s.pre[a.To], i)
+		}
+
+		if i, ok := s.index[a.To]; ok {
+			s.post[a.From] = append(s.post[a.From], i)
+		}
+	}
+
+	return s
+}
+
+// reachable returns nodes reachable from the node following arcs.
+func (s structure) reachable(from string) map[string]struct{} {
+	return walk(from, s.out)
+}
+
+// reaching returns nodes the node is reachable from.
+func (s structure) reaching(to string) map[string]struct{} {
+	return walk(to, s.in)
+}
+
+func walk(from string, arcs map[string][]string) map[string]struct{} {
+	seen := map[string]struct{}{from: {}}
+
+	queue := []string{from}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for _, next := range arcs[node] {
+			if _, ok := seen[next]; ok {
+				continue
+			}
+
+			seen[next] = struct{}{}
+			queue = append(queue, next)
+		}
+	}
+
+	return seen
+}
+
+func contains(nodes map[string]struct{}, node string) bool {
+	_, ok := nodes[node]
+
+	return ok
+}
+
+// omega stands for any number of tokens.
+const omega = -1
+
+type marking []int
+
+func (m marking) covers(other marking) bool {
+	for i := range m {
+		if m[i] != omega && (other[i] == omega || other[i] > m[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m marking) key() string {
+	return fmt.Sprint([]int(m))
+}
+
+type treeNode struct {
+	marking marking
+	parent  *treeNode
+}
+
+// boundedness builds the Karp-Miller coverability tree from the marking with
+// a token on the current or the start place.
+func (s structure) boundedness(n registry.Net, limit int) (int, []string, bool) {
+	initial := make(marking, len(s.places))
+
+	marked := n.Current
+	if marked == "" {
+		marked = n.Start
+	}
+
+	if i, ok := s.index[marked]; ok {
+		initial[i] = 1
+	}
+
+	bound := 0
+	unbounded := make([]bool, len(s.places))
+	seen := map[string]struct{}{}
+	queue := []*treeNode{{marking: initial}}
+
+	for count := 0; len(queue) > 0; count++ {
+		if count >= limit {
+			return omega, nil, true
+		}
+
+		node := queue[0]
+		queue = queue[1:]
+
+		key := node.marking.key()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		for i, tokens := range node.marking {
+			if tokens == omega {
+				unbounded[i] = true
+			}
+
+			bound = max(bound, tokens)
+		}
+
+		for _, t := range s.order {
+			next, ok := s.fire(node.marking, t)
+			if !ok {
+				continue
+			}
+
+			for ancestor := node; ancestor != nil; ancestor = ancestor.parent {
+				if !next.covers(ancestor.marking) || slices.Equal(next, ancestor.marking) {
+					continue
+				}
+
+				for i := range next {
+					if next[i] != omega && next[i] > ancestor.marking[i] {
+						next[i] = omega
+					}
+				}
+			}
+
+			queue = append(queue, &treeNode{marking: next, parent: node})
+		}
+	}
+
+	var places []string
+
+	for i, p := range s.places {
+		if unbounded[i] {
+			places = append(places, p)
+		}
+	}
+
+	if len(places) > 0 {
+		return omega, places, false
+	}
+
+	return bound, nil, false
+}
+
+// fire returns the marking after the transition fires, if it is enabled.
+func (s structure) fire(m marking, t string) (marking, bool) {
+	next := slices.Clone(m)
+
+	for _, i := range s.pre[t] {
+		switch {
+		case next[i] == omega:
+		case next[i] > 0:
+			next[i]--
+		default:
+			return nil, false
+		}
+	}
+
+	for _, i := range s.post[t] {
+		if next[i] != omega {
+			next[i]++
+		}
+	}
+
+	return next, true
+}
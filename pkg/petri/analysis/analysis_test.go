@@ -0,0 +1,92 @@
+package analysis_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/analysis"
+	"github.com/uzh13/GuePetri/pkg/petri/registry"
+)
+
+func makeNet(arcs ...registry.Arc) registry.Net {
+	return registry.Net{
+		ID:          "order",
+		Places:      []registry.Node{{ID: "new"}, {ID: "paid"}, {ID: "done"}},
+		Transitions: []registry.Node{{ID: "pay"}, {ID: "ship"}},
+		Arcs: append([]registry.Arc{
+			{From: "new", To: "pay"},
+			{From: "pay", To: "paid"},
+			{From: "paid", To: "ship"},
+			{From: "ship", To: "done"},
+		}, arcs...),
+		Start:  "new",
+		Finish: "done",
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	r := analysis.Analyze(makeNet())
+
+	assert.True(t, r.OK())
+	assert.Equal(t, analysis.Report{FinishReachable: true, Bound: 1}, r)
+
+	b := bytes.Buffer{}
+	assert.NoError(t, r.Write(&b))
+	assert.Equal(t, `unreachable: none
+finish:      true
+dead ends:   none
+traps:       none
+bound:       1
+`, b.String())
+}
+
+func TestAnalyze_Deadlocks(t *testing.T) {
+	n := makeNet(
+		registry.Arc{From: "new", To: "hold"},
+		registry.Arc{From: "hold", To: "held"},
+		registry.Arc{From: "held", To: "wait"},
+		registry.Arc{From: "wait", To: "held"},
+		registry.Arc{From: "paid", To: "lose"},
+		registry.Arc{From: "lose", To: "lost"},
+	)
+	n.Places = append(n.Places, registry.Node{ID: "held"}, registry.Node{ID: "lost"}, registry.Node{ID: "orphan"})
+	n.Transitions = append(n.Transitions, registry.Node{ID: "hold"}, registry.Node{ID: "wait"}, registry.Node{ID: "lose"}, registry.Node{ID: "adopt"})
+
+	r := analysis.Analyze(n)
+
+	assert.False(t, r.OK())
+	assert.Equal(t, []string{"orphan", "adopt"}, r.Unreachable)
+	assert.True(t, r.FinishReachable)
+	assert.Equal(t, []string{"lost"}, r.DeadEnds)
+	assert.Equal(t, []string{"held"}, r.Traps)
+	assert.Equal(t, 1, r.Bound)
+}
+
+func TestAnalyze_Unbounded(t *testing.T) {
+	n := makeNet(
+		registry.Arc{From: "pay", To: "new"},
+	)
+
+	r := analysis.Analyze(n)
+
+	assert.False(t, r.OK())
+	assert.Equal(t, -1, r.Bound)
+	assert.Equal(t, []string{"paid", "done"}, r.Unbounded)
+
+	b := bytes.Buffer{}
+	assert.NoError(t, r.Write(&b))
+	assert.Contains(t, b.String(), "bound:       unbounded on paid, done\n")
+}
+
+func TestAnalyzeLimit(t *testing.T) {
+	n := makeNet(registry.Arc{From: "pay", To: "done"})
+
+	assert.Equal(t, 2, analysis.Analyze(n).Bound)
+
+	r := analysis.AnalyzeLimit(n, 2)
+	assert.True(t, r.Incomplete)
+	assert.Equal(t, -1, r.Bound)
+	assert.False(t, r.OK())
+}
@@ -0,0 +1,292 @@
+// Package definition loads graphs from declarative YAML or JSON documents.
+//
+//	id: order
+//	handler: orders
+//	start: new
+//	finish: done
+//	places:
+//	  - id: new
+//	    handler: intake
+//	    timeout: 30s
+//	  - id: paid
+//	  - id: done
+//	transitions:
+//	  - id: pay
+//	    guard: has_funds
+//	arcs:
+//	  - {from: new, to: pay}
+//	  - {from: pay, to: paid}
+//
+// Handler and guard names are resolved by a registry.Registry, timeouts bound
+// each handler call of the node.
+package definition
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/registry"
+)
+
+var ErrUnknownFormat = errors.New("unknown definition format")
+
+type Format int
+
+const (
+	YAML Format = iota
+	JSON
+)
+
+func (f Format) String() string {
+	switch f {
+	case YAML:
+		return "yaml"
+	case JSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// FormatOf detects the format by the file extension.
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".json":
+		return JSON, nil
+	default:
+		return 0, fmt.Errorf("%s: %w", path, ErrUnknownFormat)
+	}
+}
+
+type Document struct {
+	ID          string       `json:"id" yaml:"id"`
+	Handler     string       `json:"handler,omitempty" yaml:"handler,omitempty"`
+	Start       string       `json:"start" yaml:"start"`
+	Finish      string       `json:"finish" yaml:"finish"`
+	Current     string       `json:"current,omitempty" yaml:"current,omitempty"`
+	Places      []Place      `json:"places" yaml:"places"`
+	Transitions []Transition `json:"transitions" yaml:"transitions"`
+	Arcs        []Arc        `json:"arcs" yaml:"arcs"`
+}
+
+type Place struct {
+	ID      string   `json:"id" yaml:"id"`
+	Handler string   `json:"handler,omitempty" yaml:"handler,omitempty"`
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+type Transition struct {
+	ID      string   `json:"id" yaml:"id"`
+	Handler string   `json:"handler,omitempty" yaml:"handler,omitempty"`
+	Guard   string   `json:"guard,omitempty" yaml:"guard,omitempty"`
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+}
+
+type Arc struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+}
+
+// Duration is a time.Duration written as "1m30s".
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return fmt.Errorf("duration: %w", err)
+	}
+
+	return d.parse(s)
+}
+
+func (d Duration) MarshalYAML() (any, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	return d.parse(node.Value)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("duration: %w", err)
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// Net converts the document to the net built by a registry.
+func (d Document) Net() registry.Net {
+	n := registry.Net{
+		ID:      d.ID,
+		Handler: d.Handler,
+		Start:   d.Start,
+		Finish:  d.Finish,
+		Current: d.Current,
+	}
+
+	for _, p := range d.Places {
+		n.Places = append(n.Places, registry.Node{ID: p.ID, Handler: p.Handler, Timeout: time.Duration(p.Timeout)})
+	}
+
+	for _, t := range d.Transitions {
+		n.Transitions = append(n.Transitions, registry.Node{ID: t.ID, Handler: t.Handler, Guard: t.Guard, Timeout: time.Duration(t.Timeout)})
+	}
+
+	for _, a := range d.Arcs {
+		n.Arcs = append(n.Arcs, registry.Arc{From: a.From, To: a.To})
+	}
+
+	return n
+}
+
+// FromNet converts a net to a document.
+func FromNet(n registry.Net) Document {
+	d := Document{
+		ID:      n.ID,
+		Handler: n.Handler,
+		Start:   n.Start,
+		Finish:  n.Finish,
+		Current: n.Current,
+	}
+
+	for _, p := range n.Places {
+		d.Places = append(d.Places, Place{ID: p.ID, Handler: p.Handler, Timeout: Duration(p.Timeout)})
+	}
+
+	for _, t := range n.Transitions {
+		d.Transitions = append(d.Transitions, Transition{ID: t.ID, Handler: t.Handler, Guard: t.Guard, Timeout: Duration(t.Timeout)})
+	}
+
+	for _, a := range n.Arcs {
+		d.Arcs = append(d.Arcs, Arc{From: a.From, To: a.To})
+	}
+
+	return d
+}
+
+// Decode reads a document. Unknown fields are rejected.
+func Decode(r io.Reader, format Format) (Document, error) {
+	d := Document{}
+
+	var err error
+	switch format {
+	case YAML:
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		err = decoder.Decode(&d)
+	case JSON:
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&d)
+	default:
+		return d, fmt.Errorf("%v: %w", format, ErrUnknownFormat)
+	}
+
+	if err != nil {
+		return d, fmt.Errorf("decoding %v definition: %w", format, err)
+	}
+
+	return d, nil
+}
+
+// Encode writes the document.
+func Encode(w io.Writer, d Document, format Format) error {
+	var err error
+	switch format {
+	case YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		err = encoder.Encode(d)
+		if err == nil {
+			err = encoder.Close()
+		}
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(d)
+	default:
+		return fmt.Errorf("%v: %w", format, ErrUnknownFormat)
+	}
+
+	if err != nil {
+		return fmt.Errorf("encoding %v definition: %w", format, err)
+	}
+
+	return nil
+}
+
+// Load reads a document and builds its graph with handlers from the registry.
+func Load[T any](r io.Reader, format Format, reg *registry.Registry[T]) (*graph.Petri[T, string], error) {
+	d, err := Decode(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	return reg.Build(d.Net())
+}
+
+// LoadFile reads a document in the format of the file extension and builds
+// its graph with handlers from the registry.
+func LoadFile[T any](path string, reg *registry.Registry[T]) (*graph.Petri[T, string], error) {
+	d, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return reg.Build(d.Net())
+}
+
+// Build creates the graph of a document with default handlers and guards
+// accepting every signal, for tools running a definition without the
+// application code behind its names.
+func Build(d Document) (*graph.Petri[string, string], error) {
+	reg := registry.Default[string]()
+	for _, t := range d.Transitions {
+		if t.Guard != "" {
+			reg.RegisterGuard(t.Guard, func(string) bool { return true })
+		}
+	}
+
+	return reg.Build(d.Net())
+}
+
+// ReadFile reads a document in the format of the file extension.
+func ReadFile(path string) (Document, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return Document{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Document{}, fmt.Errorf("reading definition: %w", err)
+	}
+	defer f.Close()
+
+	return Decode(f, format)
+}
@@ -0,0 +1,112 @@
+package definition_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/definition"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/registry"
+)
+
+const orderYAML = `id: order
+start: new
+finish: done
+places:
+  - id: new
+    timeout: 1s
+  - id: paid
+  - id: done
+transitions:
+  - id: pay
+    guard: positive
+  - id: ship
+    timeout: 1m30s
+arcs:
+  - {from: new, to: pay}
+  - {from: pay, to: paid}
+  - {from: paid, to: ship}
+  - {from: ship, to: done}
+`
+
+func TestLoad_YAML(t *testing.T) {
+	reg := registry.Default[string]().
+		RegisterGuard("positive", func(signal string) bool { return !strings.HasPrefix(signal, "-") })
+
+	g, err := definition.Load(strings.NewReader(orderYAML), definition.YAML, reg)
+	assert.NoError(t, err)
+
+	assert.NoError(t, g.Act("pay"))
+	assert.NoError(t, g.Act("ship"))
+	assert.True(t, g.IsOnFinish())
+}
+
+func TestBuild(t *testing.T) {
+	d, err := definition.Decode(strings.NewReader(orderYAML), definition.YAML)
+	assert.NoError(t, err)
+
+	g, err := definition.Build(d)
+	assert.NoError(t, err)
+
+	assert.NoError(t, g.Act("pay"))
+	assert.ErrorIs(t, g.Act("pay"), graph.ErrSignalIgnored)
+	assert.NoError(t, g.Act("ship"))
+	assert.True(t, g.IsOnFinish())
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	d, err := definition.Decode(strings.NewReader(orderYAML), definition.YAML)
+	assert.NoError(t, err)
+	assert.Equal(t, definition.Duration(90*time.Second), d.Transitions[1].Timeout)
+	assert.Equal(t, 90*time.Second, d.Net().Transitions[1].Timeout)
+
+	b := bytes.Buffer{}
+	assert.NoError(t, definition.Encode(&b, d, definition.YAML))
+	assert.Contains(t, b.String(), "timeout: 1m30s")
+
+	b.Reset()
+	assert.NoError(t, definition.Encode(&b, d, definition.JSON))
+	assert.Contains(t, b.String(), `"timeout": "1m30s"`)
+	assert.NotContains(t, b.String(), `"guard": ""`)
+
+	fromJSON, err := definition.Decode(&b, definition.JSON)
+	assert.NoError(t, err)
+	assert.Equal(t, d, fromJSON)
+	assert.Equal(t, d, definition.FromNet(d.Net()))
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	_, err := definition.Decode(strings.NewReader("id: x\nplacez: []\n"), definition.YAML)
+	assert.ErrorContains(t, err, "field placez not found")
+
+	_, err = definition.Decode(strings.NewReader(`{"id": "x", "placez": []}`), definition.JSON)
+	assert.ErrorContains(t, err, `unknown field "placez"`)
+
+	_, err = definition.Decode(strings.NewReader(`{"places": [{"id": "p", "timeout": "soon"}]}`), definition.JSON)
+	assert.ErrorContains(t, err, "duration")
+
+	_, err = definition.Decode(strings.NewReader(""), definition.Format(7))
+	assert.ErrorIs(t, err, definition.ErrUnknownFormat)
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "order.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(orderYAML), 0o600))
+
+	_, err := definition.LoadFile(path, registry.Default[string]())
+	assert.ErrorIs(t, err, registry.ErrUnknownGuard)
+
+	g, err := definition.LoadFile(path, registry.Default[string]().RegisterGuard("positive", func(string) bool { return false }))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, g.Act("pay"), graph.ErrSignalIgnored)
+
+	_, err = definition.LoadFile(filepath.Join(dir, "order.toml"), registry.Default[string]())
+	assert.ErrorIs(t, err, definition.ErrUnknownFormat)
+}
@@ -0,0 +1,9 @@
+package metrics
+
+// Tracked returns the number of graphs whose start time is kept.
+func (m *Instrumentation[T, V]) Tracked() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.started)
+}
@@ -0,0 +1,198 @@
+// Package metrics reports queue depth, firings, handler latencies, errors and
+// graph completion and cancellation times of a PetriQueue to a Recorder.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// Recorder receives the measurements. IDs are formatted with fmt.Sprint.
+type Recorder interface {
+	SetQueueDepth(level int, depth int)
+	SetActiveGraphs(count int)
+	IncFiring(transition string)
+	ObserveHandler(stage string, duration time.Duration)
+	IncError(stage string)
+	ObserveCompletion(duration time.Duration)
+	ObserveCancellation(duration time.Duration)
+}
+
+// Instrumentation measures one queue. Sample is called after every Act, call
+// it directly to refresh the gauges after other queue changes.
+type Instrumentation[T any, V comparable] struct {
+	queue    *aggregate.PetriQueue[T, V]
+	recorder Recorder
+	now      func() time.Time
+
+	mu      sync.Mutex
+	levels  map[int]struct{}
+	started map[V]time.Time
+	handled map[V]V
+}
+
+// Instrument reports the queue and the handler calls of its graphs to the
+// recorder.
+func Instrument[T any, V comparable](q *aggregate.PetriQueue[T, V], recorder Recorder) *Instrumentation[T, V] {
+	m := &Instrumentation[T, V]{
+		queue:    q,
+		recorder: recorder,
+		now:      time.Now,
+		levels:   map[int]struct{}{},
+		started:  map[V]time.Time{},
+		handled:  map[V]V{},
+	}
+
+	q.UseAct(m.act).Use(m.intercept)
+
+	return m
+}
+
+// Sample sets the queue depth per level and the number of started graphs.
+// Levels that became empty are reported with zero depth. Graphs that left the
+// queue without finishing, as dead letters do, are no longer followed.
+func (m *Instrumentation[T, V]) Sample() {
+	q := m.queue.GetQueue()
+	if q == nil {
+		return
+	}
+
+	depths := q.Depths()
+
+	active := 0
+	queued := map[V]struct{}{}
+	q.Range(func(_ int, obj *graph.Petri[T, V]) bool {
+		if obj.Current != nil {
+			active++
+		}
+
+		queued[obj.ID] = struct{}{}
+
+		return true
+	})
+
+	m.mu.Lock()
+	for id := range m.started {
+		if _, ok := queued[id]; !ok {
+			delete(m.started, id)
+		}
+	}
+
+	for id := range m.handled {
+		if _, ok := queued[id]; !ok {
+			delete(m.handled, id)
+		}
+	}
+
+	for level := range m.levels {
+		if _, ok := depths[level]; !ok {
+			depths[level] = 0
+			delete(m.levels, level)
+		}
+	}
+
+	for level, depth := range depths {
+		if depth != 0 {
+			m.levels[level] = struct{}{}
+		}
+	}
+	m.mu.Unlock()
+
+	for level, depth := range depths {
+		m.recorder.SetQueueDepth(level, depth)
+	}
+
+	m.recorder.SetActiveGraphs(active)
+}
+
+func (m *Instrumentation[T, V]) act(ctx context.Context, _ T, next func(context.Context) (*aggregate.ActReport[V], error)) (*aggregate.ActReport[V], error) {
+	report, err := next(ctx)
+	m.Sample()
+
+	return report, err
+}
+
+func (m *Instrumentation[T, V]) intercept(ctx context.Context, call graph.Call[T, V], next func(context.Context) error) error {
+	at := m.now()
+	err := next(ctx)
+	m.recorder.ObserveHandler(call.Stage.String(), m.now().Sub(at))
+
+	if err != nil && !errors.Is(err, graph.ErrSignalIgnored) {
+		m.recorder.IncError(call.Stage.String())
+	}
+
+	finishing := false
+	if call.Stage == graph.StageGraphHandleOut {
+		finishing = m.finishing(call.GraphID)
+	}
+
+	m.track(call, err, at, finishing)
+
+	return err
+}
+
+// finishing reports whether the graph leaves the queue from its finish place.
+// A graph cancelled on removal has already left the queue, an aborted one is
+// back on an earlier place.
+func (m *Instrumentation[T, V]) finishing(id V) bool {
+	g, _, ok := m.queue.FindGraph(id)
+
+	return ok && g.IsOnFinish()
+}
+
+// track follows the stages of a graph: a transition counts as fired once the
+// next place accepts the token, completion time runs from the graph start to
+// its finish and cancellation time to its cancel.
+func (m *Instrumentation[T, V]) track(call graph.Call[T, V], err error, at time.Time, finishing bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch call.Stage {
+	case graph.StageGraphHandleIn:
+		if err == nil {
+			m.started[call.GraphID] = at
+		}
+	case graph.StageHandle:
+		if err == nil {
+			m.handled[call.GraphID] = call.NodeID
+		}
+	case graph.StagePlaceHandleOut:
+		if err != nil {
+			delete(m.handled, call.GraphID)
+		}
+	case graph.StagePlaceHandleIn:
+		transition, ok := m.handled[call.GraphID]
+		delete(m.handled, call.GraphID)
+
+		if ok && err == nil {
+			m.recorder.IncFiring(fmt.Sprint(transition))
+		}
+	case graph.StageGraphHandleOut:
+		started, ok := m.started[call.GraphID]
+		if !ok || err != nil {
+			return
+		}
+
+		delete(m.started, call.GraphID)
+
+		if finishing {
+			m.recorder.ObserveCompletion(m.now().Sub(started))
+		} else {
+			m.recorder.ObserveCancellation(m.now().Sub(started))
+		}
+	case graph.StageGraphCancel:
+		started, ok := m.started[call.GraphID]
+		if !ok || err != nil {
+			return
+		}
+
+		delete(m.started, call.GraphID)
+		m.recorder.ObserveCancellation(m.now().Sub(started))
+	}
+}
@@ -0,0 +1,147 @@
+package metrics_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/metrics"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+type recorder struct {
+	depth         map[int]int
+	active        int
+	firings       map[string]int
+	errors        map[string]int
+	handlers      map[string]int
+	completions   int
+	cancellations int
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		depth:    map[int]int{},
+		firings:  map[string]int{},
+		errors:   map[string]int{},
+		handlers: map[string]int{},
+	}
+}
+
+func (r *recorder) SetQueueDepth(level int, depth int)           { r.depth[level] = depth }
+func (r *recorder) SetActiveGraphs(count int)                    { r.active = count }
+func (r *recorder) IncFiring(transition string)                  { r.firings[transition]++ }
+func (r *recorder) ObserveHandler(stage string, _ time.Duration) { r.handlers[stage]++ }
+func (r *recorder) IncError(stage string)                        { r.errors[stage]++ }
+func (r *recorder) ObserveCompletion(_ time.Duration)            { r.completions++ }
+func (r *recorder) ObserveCancellation(_ time.Duration)          { r.cancellations++ }
+
+type graphHandler struct{}
+
+func (graphHandler) HandleIn() error  { return nil }
+func (graphHandler) HandleOut() error { return nil }
+
+type placeHandler struct {
+	next *graph.Transition[string, string]
+	err  error
+}
+
+func (h *placeHandler) HandleIn(*graph.Place[string, string]) error  { return h.err }
+func (h *placeHandler) HandleOut(*graph.Place[string, string]) error { return nil }
+
+func (h *placeHandler) ChooseTo(string) (*graph.Transition[string, string], error) {
+	return h.next, nil
+}
+
+type transitionHandler struct {
+	next *graph.Place[string, string]
+}
+
+func (h *transitionHandler) Handle(*graph.Place[string, string], string) (*graph.Place[string, string], error) {
+	return h.next, nil
+}
+
+// makeGraph builds start -> t1 -> middle -> t2 -> finish.
+func makeGraph(id string, middleErr error) *graph.Petri[string, string] {
+	finish := graph.NewPlace[string, string]("finish", &placeHandler{})
+	t2 := graph.NewTransition[string, string]("t2", &transitionHandler{next: finish}).AddTo(finish)
+	middle := graph.NewPlace[string, string]("middle", &placeHandler{next: t2}).AddTransition(t2)
+	t1 := graph.NewTransition[string, string]("t1", &transitionHandler{next: middle}).AddTo(middle)
+	start := graph.NewPlace[string, string]("start", &placeHandler{next: t1}).AddTransition(t1)
+	middle.Handler.(*placeHandler).err = middleErr
+
+	return graph.NewPetri[string, string](id, graphHandler{}).
+		SetStartPlace(start).
+		SetFinishPlace(finish)
+}
+
+func TestInstrument(t *testing.T) {
+	r := newRecorder()
+	q := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+	m := metrics.Instrument(q, r)
+
+	assert.NoError(t, q.AddGraph(1, makeGraph("graph1", nil)))
+	assert.NoError(t, q.AddGraph(0, makeGraph("graph2", nil)))
+
+	m.Sample()
+	assert.Equal(t, map[int]int{0: 1, 1: 1}, r.depth)
+	assert.Equal(t, 1, r.active)
+
+	assert.NoError(t, q.Act("sig"))
+	assert.Equal(t, map[string]int{"t1": 1}, r.firings)
+	assert.Equal(t, 1, r.handlers["choose_to"])
+
+	assert.NoError(t, q.Act("sig"))
+	assert.Equal(t, map[string]int{"t1": 2, "t2": 1}, r.firings)
+	assert.Equal(t, 1, r.completions)
+	assert.Equal(t, map[int]int{0: 1, 1: 0}, r.depth)
+	assert.Equal(t, 1, r.active)
+	assert.Empty(t, r.errors)
+}
+
+func TestInstrument_Errors(t *testing.T) {
+	r := newRecorder()
+	q := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+	metrics.Instrument(q, r)
+
+	assert.NoError(t, q.AddGraph(0, makeGraph("graph1", errors.New("closed"))))
+	assert.Error(t, q.Act("sig"))
+
+	assert.Equal(t, map[string]int{"place_handle_in": 1}, r.errors)
+	assert.Empty(t, r.firings)
+}
+
+func TestInstrument_Cancel(t *testing.T) {
+	r := newRecorder()
+	q := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0")
+	m := metrics.Instrument(q, r)
+
+	assert.NoError(t, q.AddGraph(0, makeGraph("graph1", nil)))
+	assert.NoError(t, q.AddGraph(0, makeGraph("graph2", nil)))
+	assert.Equal(t, 1, m.Tracked())
+
+	_, err := q.RemoveGraph("graph1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, r.cancellations)
+	assert.Equal(t, 0, r.completions)
+	assert.Equal(t, 1, m.Tracked())
+}
+
+func TestInstrument_DeadLetter(t *testing.T) {
+	r := newRecorder()
+	q := aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0").
+		SetErrorPolicy(aggregate.ErrorPolicy{DeadLetter: true})
+	m := metrics.Instrument(q, r)
+
+	assert.NoError(t, q.AddGraph(0, makeGraph("graph1", errors.New("closed"))))
+	assert.NoError(t, q.Act("sig"))
+
+	assert.Len(t, q.DeadLetters(), 1)
+	assert.Equal(t, 0, m.Tracked())
+	assert.Equal(t, 0, r.completions+r.cancellations)
+}
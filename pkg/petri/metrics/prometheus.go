@@ -0,0 +1,220 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the histogram upper bounds in seconds.
+var DefaultBuckets = []float64{.001, .005, .01, .05, .1, .5, 1, 5, 10, 60}
+
+// Prometheus is a Recorder keeping the measurements in memory and serving
+// them in the Prometheus text exposition format.
+type Prometheus struct {
+	namespace string
+	buckets   []float64
+
+	mu            sync.Mutex
+	depth         map[int]float64
+	active        float64
+	firings       map[string]float64
+	errors        map[string]float64
+	handlers      map[string]*histogram
+	completions   *histogram
+	cancellations *histogram
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheus creates the recorder. Metric names are prefixed with the
+// namespace, nil buckets mean DefaultBuckets.
+func NewPrometheus(namespace string, buckets []float64) *Prometheus {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+
+	buckets = append([]float64(nil), buckets...)
+	slices.Sort(buckets)
+
+	return &Prometheus{
+		namespace:     namespace,
+		buckets:       buckets,
+		depth:         map[int]float64{},
+		firings:       map[string]float64{},
+		errors:        map[string]float64{},
+		handlers:      map[string]*histogram{},
+		completions:   &histogram{counts: make([]uint64, len(buckets))},
+		cancellations: &histogram{counts: make([]uint64, len(buckets))},
+	}
+}
+
+func (p *Prometheus) SetQueueDepth(level int, depth int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.depth[level] = float64(depth)
+}
+
+func (p *Prometheus) SetActiveGraphs(count int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.active = float64(count)
+}
+
+func (p *Prometheus) IncFiring(transition string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.firings[transition]++
+}
+
+func (p *Prometheus) ObserveHandler(stage string, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	h, ok := p.handlers[stage]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.handlers[stage] = h
+	}
+
+	h.observe(p.buckets, duration.Seconds())
+}
+
+func (p *Prometheus) IncError(stage string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.errors[stage]++
+}
+
+func (p *Prometheus) ObserveCompletion(duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.completions.observe(p.buckets, duration.Seconds())
+}
+
+func (p *Prometheus) ObserveCancellation(duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cancellations.observe(p.buckets, duration.Seconds())
+}
+
+// ServeHTTP writes the metrics, so the recorder can be mounted as /metrics.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_, _ = p.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the text exposition format.
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b := strings.Builder{}
+
+	p.header(&b, "queue_depth", "gauge", "Number of queued graphs per priority level.")
+	for _, level := range slices.Sorted(maps.Keys(p.depth)) {
+		p.sample(&b, "queue_depth", `level="`+strconv.Itoa(level)+`"`, p.depth[level])
+	}
+
+	p.header(&b, "active_graphs", "gauge", "Number of started graphs in the queue.")
+	p.sample(&b, "active_graphs", "", p.active)
+
+	p.header(&b, "firings_total", "counter", "Committed firings per transition.")
+	for _, transition := range slices.Sorted(maps.Keys(p.firings)) {
+		p.sample(&b, "firings_total", label("transition", transition), p.firings[transition])
+	}
+
+	p.header(&b, "handler_errors_total", "counter", "Failed handler calls per stage.")
+	for _, stage := range slices.Sorted(maps.Keys(p.errors)) {
+		p.sample(&b, "handler_errors_total", label("stage", stage), p.errors[stage])
+	}
+
+	p.header(&b, "handler_duration_seconds", "histogram", "Handler call latency per stage.")
+	for _, stage := range slices.Sorted(maps.Keys(p.handlers)) {
+		p.histogram(&b, "handler_duration_seconds", label("stage", stage), p.handlers[stage])
+	}
+
+	p.header(&b, "graph_completion_seconds", "histogram", "Time from graph start to its finish.")
+	p.histogram(&b, "graph_completion_seconds", "", p.completions)
+
+	p.header(&b, "graph_cancellation_seconds", "histogram", "Time from graph start to its cancellation.")
+	p.histogram(&b, "graph_cancellation_seconds", "", p.cancellations)
+
+	n, err := io.WriteString(w, b.String())
+
+	return int64(n), err
+}
+
+func (p *Prometheus) name(metric string) string {
+	if p.namespace == "" {
+		return metric
+	}
+
+	return p.namespace + "_" + metric
+}
+
+func (p *Prometheus) header(b *strings.Builder, metric, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", p.name(metric), help, p.name(metric), kind)
+}
+
+func (p *Prometheus) sample(b *strings.Builder, metric, labels string, value float64) {
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+
+	fmt.Fprintf(b, "%s%s %s\n", p.name(metric), labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+func (p *Prometheus) histogram(b *strings.Builder, metric, labels string, h *histogram) {
+	prefix := labels
+	if prefix != "" {
+		prefix += ","
+	}
+
+	var cumulative uint64
+	for i, upper := range p.buckets {
+		cumulative += h.counts[i]
+		le := strconv.FormatFloat(upper, 'g', -1, 64)
+		p.sample(b, metric+"_bucket", prefix+`le="`+le+`"`, float64(cumulative))
+	}
+
+	p.sample(b, metric+"_bucket", prefix+`le="+Inf"`, float64(h.count))
+	p.sample(b, metric+"_sum", labels, h.sum)
+	p.sample(b, metric+"_count", labels, float64(h.count))
+}
+
+func (h *histogram) observe(buckets []float64, value float64) {
+	h.count++
+	h.sum += value
+
+	for i, upper := range buckets {
+		if value <= upper {
+			h.counts[i]++
+
+			return
+		}
+	}
+}
+
+func label(name, value string) string {
+	return name + `="` + escaper.Replace(value) + `"`
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
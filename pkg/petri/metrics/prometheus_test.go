@@ -0,0 +1,70 @@
+package metrics_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/metrics"
+)
+
+func TestPrometheus(t *testing.T) {
+	expected := `# HELP petri_queue_depth Number of queued graphs per priority level.
+# TYPE petri_queue_depth gauge
+petri_queue_depth{level="0"} 2
+petri_queue_depth{level="3"} 1
+# HELP petri_active_graphs Number of started graphs in the queue.
+# TYPE petri_active_graphs gauge
+petri_active_graphs 1
+# HELP petri_firings_total Committed firings per transition.
+# TYPE petri_firings_total counter
+petri_firings_total{transition="a\"b"} 1
+petri_firings_total{transition="t1"} 2
+# HELP petri_handler_errors_total Failed handler calls per stage.
+# TYPE petri_handler_errors_total counter
+petri_handler_errors_total{stage="handle"} 1
+# HELP petri_handler_duration_seconds Handler call latency per stage.
+# TYPE petri_handler_duration_seconds histogram
+petri_handler_duration_seconds_bucket{stage="handle",le="0.1"} 1
+petri_handler_duration_seconds_bucket{stage="handle",le="1"} 2
+petri_handler_duration_seconds_bucket{stage="handle",le="+Inf"} 3
+petri_handler_duration_seconds_sum{stage="handle"} 2.55
+petri_handler_duration_seconds_count{stage="handle"} 3
+# HELP petri_graph_completion_seconds Time from graph start to its finish.
+# TYPE petri_graph_completion_seconds histogram
+petri_graph_completion_seconds_bucket{le="0.1"} 0
+petri_graph_completion_seconds_bucket{le="1"} 1
+petri_graph_completion_seconds_bucket{le="+Inf"} 1
+petri_graph_completion_seconds_sum 1
+petri_graph_completion_seconds_count 1
+# HELP petri_graph_cancellation_seconds Time from graph start to its cancellation.
+# TYPE petri_graph_cancellation_seconds histogram
+petri_graph_cancellation_seconds_bucket{le="0.1"} 1
+petri_graph_cancellation_seconds_bucket{le="1"} 1
+petri_graph_cancellation_seconds_bucket{le="+Inf"} 1
+petri_graph_cancellation_seconds_sum 0.05
+petri_graph_cancellation_seconds_count 1
+`
+
+	p := metrics.NewPrometheus("petri", []float64{1, .1})
+	p.SetQueueDepth(3, 1)
+	p.SetQueueDepth(0, 2)
+	p.SetActiveGraphs(1)
+	p.IncFiring("t1")
+	p.IncFiring("t1")
+	p.IncFiring(`a"b`)
+	p.IncError("handle")
+	p.ObserveHandler("handle", 50*time.Millisecond)
+	p.ObserveHandler("handle", 500*time.Millisecond)
+	p.ObserveHandler("handle", 2*time.Second)
+	p.ObserveCompletion(time.Second)
+	p.ObserveCancellation(50 * time.Millisecond)
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, expected, w.Body.String())
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,326 @@
+// Package net builds graphs with a fluent API instead of wiring places,
+// transitions and handlers by hand.
+//
+//	n := net.New[string, string]("order").Start("new").Finish("done")
+//	n.Place("new").On("pay").Fire("pay").To("paid").
+//		On("ship").Fire("ship").To("done")
+//	g, err := n.Build()
+//
+// A place fires the transition bound to the signal it receives and ignores
+// other signals, a transition moves the token to its only output place. Build
+// checks the whole net and returns every problem found in it.
+package net
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+var ErrInvalidNet = errors.New("invalid net")
+
+// Builder collects the structure of a graph. Signals are compared with ==, so
+// the signal type has to be comparable.
+type Builder[T comparable, V comparable] struct {
+	id          V
+	handler     graph.PetriHandler
+	start       *V
+	finish      *V
+	places      map[V]*placeSpec[T, V]
+	transitions map[V]*transitionSpec[T, V]
+	placeIDs    []V
+	transIDs    []V
+}
+
+type placeSpec[T comparable, V comparable] struct {
+	id    V
+	safe  bool
+	enter func(from *graph.Place[T, V]) error
+	leave func(to *graph.Place[T, V]) error
+	rules []*rule[T, V]
+}
+
+type rule[T comparable, V comparable] struct {
+	signal     T
+	transition *V
+}
+
+type transitionSpec[T comparable, V comparable] struct {
+	id V
+	do func(from *graph.Place[T, V], signal T) error
+	to []V
+}
+
+func New[T comparable, V comparable](id V) *Builder[T, V] {
+	return &Builder[T, V]{
+		id:          id,
+		places:      map[V]*placeSpec[T, V]{},
+		transitions: map[V]*transitionSpec[T, V]{},
+	}
+}
+
+func (b *Builder[T, V]) Start(id V) *Builder[T, V] {
+	b.start = &id
+
+	return b
+}
+
+func (b *Builder[T, V]) Finish(id V) *Builder[T, V] {
+	b.finish = &id
+
+	return b
+}
+
+// Handler sets the graph handler. Graphs without one do nothing on start and
+// finish.
+func (b *Builder[T, V]) Handler(handler graph.PetriHandler) *Builder[T, V] {
+	b.handler = handler
+
+	return b
+}
+
+// Place declares a place, or returns the one declared with the ID before.
+func (b *Builder[T, V]) Place(id V) *PlaceBuilder[T, V] {
+	spec, ok := b.places[id]
+	if !ok {
+		spec = &placeSpec[T, V]{id: id}
+		b.places[id] = spec
+		b.placeIDs = append(b.placeIDs, id)
+	}
+
+	return &PlaceBuilder[T, V]{builder: b, spec: spec}
+}
+
+// Transition declares a transition, or returns the one declared with the ID
+// before.
+func (b *Builder[T, V]) Transition(id V) *TransitionBuilder[T, V] {
+	spec, ok := b.transitions[id]
+	if !ok {
+		spec = &transitionSpec[T, V]{id: id}
+		b.transitions[id] = spec
+		b.transIDs = append(b.transIDs, id)
+	}
+
+	return &TransitionBuilder[T, V]{builder: b, spec: spec}
+}
+
+type PlaceBuilder[T comparable, V comparable] struct {
+	builder *Builder[T, V]
+	spec    *placeSpec[T, V]
+}
+
+// On binds the signal to the transition given to Fire.
+func (p *PlaceBuilder[T, V]) On(signal T) *Binding[T, V] {
+	r := &rule[T, V]{signal: signal}
+	p.spec.rules = append(p.spec.rules, r)
+
+	return &Binding[T, V]{place: p, rule: r}
+}
+
+// Safe marks the place where the graph may be preempted.
+func (p *PlaceBuilder[T, V]) Safe() *PlaceBuilder[T, V] {
+	p.spec.safe = true
+
+	return p
+}
+
+// Enter sets the function called when the token enters the place.
+func (p *PlaceBuilder[T, V]) Enter(fn func(from *graph.Place[T, V]) error) *PlaceBuilder[T, V] {
+	p.spec.enter = fn
+
+	return p
+}
+
+// Leave sets the function called when the token leaves the place.
+func (p *PlaceBuilder[T, V]) Leave(fn func(to *graph.Place[T, V]) error) *PlaceBuilder[T, V] {
+	p.spec.leave = fn
+
+	return p
+}
+
+// Place switches to another place of the net.
+func (p *PlaceBuilder[T, V]) Place(id V) *PlaceBuilder[T, V] {
+	return p.builder.Place(id)
+}
+
+type Binding[T comparable, V comparable] struct {
+	place *PlaceBuilder[T, V]
+	rule  *rule[T, V]
+}
+
+// Fire declares the transition fired by the signal and the arc leading to it.
+func (b *Binding[T, V]) Fire(id V) *TransitionBuilder[T, V] {
+	b.rule.transition = &id
+
+	return b.place.builder.Transition(id)
+}
+
+type TransitionBuilder[T comparable, V comparable] struct {
+	builder *Builder[T, V]
+	spec    *transitionSpec[T, V]
+}
+
+// To declares the output place of the transition and returns it.
+func (t *TransitionBuilder[T, V]) To(id V) *PlaceBuilder[T, V] {
+	for _, to := range t.spec.to {
+		if to == id {
+			return t.builder.Place(id)
+		}
+	}
+
+	t.spec.to = append(t.spec.to, id)
+
+	return t.builder.Place(id)
+}
+
+// Do sets the function called when the transition fires. An error keeps the
+// token on the place it came from.
+func (t *TransitionBuilder[T, V]) Do(fn func(from *graph.Place[T, V], signal T) error) *TransitionBuilder[T, V] {
+	t.spec.do = fn
+
+	return t
+}
+
+// Build validates the net and creates the graph. All problems found in the
+// net are returned together.
+func (b *Builder[T, V]) Build() (*graph.Petri[T, V], error) {
+	errs := b.validate()
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("net %v: %w: %w", b.id, ErrInvalidNet, errors.Join(errs...))
+	}
+
+	places := make(map[V]*graph.Place[T, V], len(b.places))
+	for _, id := range b.placeIDs {
+		places[id] = graph.NewPlace[T, V](id, nil).SetSafe(b.places[id].safe)
+	}
+
+	transitions := make(map[V]*graph.Transition[T, V], len(b.transitions))
+	for _, id := range b.transIDs {
+		spec := b.transitions[id]
+		to := places[spec.to[0]]
+		transitions[id] = graph.NewTransition[T, V](id, transitionHandler[T, V]{to: to, do: spec.do}).AddTo(to)
+	}
+
+	for _, id := range b.placeIDs {
+		spec := b.places[id]
+		handler := &placeHandler[T, V]{place: places[id], enter: spec.enter, leave: spec.leave}
+
+		for _, r := range spec.rules {
+			t := transitions[*r.transition]
+			places[id].AddTransition(t)
+			handler.rules = append(handler.rules, route[T, V]{signal: r.signal, transition: t})
+		}
+
+		places[id].Handler = handler
+	}
+
+	handler := b.handler
+	if handler == nil {
+		handler = noop{}
+	}
+
+	return graph.NewPetri[T, V](b.id, handler).
+		SetStartPlace(places[*b.start]).
+		SetFinishPlace(places[*b.finish]), nil
+}
+
+func (b *Builder[T, V]) validate() []error {
+	var errs []error
+
+	for _, role := range []struct {
+		name string
+		id   *V
+	}{
+		{name: "start", id: b.start},
+		{name: "finish", id: b.finish},
+	} {
+		if role.id == nil {
+			errs = append(errs, fmt.Errorf("%s place is not set", role.name))
+
+			continue
+		}
+
+		if _, ok := b.places[*role.id]; !ok {
+			errs = append(errs, fmt.Errorf("%s place %v is not defined", role.name, *role.id))
+		}
+	}
+
+	for _, id := range b.placeIDs {
+		if _, ok := b.transitions[id]; ok {
+			errs = append(errs, fmt.Errorf("%v is both a place and a transition", id))
+		}
+
+		fired := map[T]V{}
+		for _, r := range b.places[id].rules {
+			if r.transition == nil {
+				errs = append(errs, fmt.Errorf("place %v: signal %v fires no transition", id, r.signal))
+
+				continue
+			}
+
+			other, ok := fired[r.signal]
+			if ok && other != *r.transition {
+				errs = append(errs, fmt.Errorf("place %v: signal %v fires both %v and %v", id, r.signal, other, *r.transition))
+
+				continue
+			}
+
+			fired[r.signal] = *r.transition
+		}
+	}
+
+	for _, id := range b.transIDs {
+		switch to := b.transitions[id].to; len(to) {
+		case 0:
+			errs = append(errs, fmt.Errorf("transition %v leads to no place", id))
+		case 1:
+		default:
+			errs = append(errs, fmt.Errorf("transition %v leads to several places %v", id, to))
+		}
+	}
+
+	if len(errs) > 0 || b.start == nil || b.finish == nil {
+		return errs
+	}
+
+	reached := b.reachable()
+
+	for _, id := range b.placeIDs {
+		if _, ok := reached[id]; !ok {
+			errs = append(errs, fmt.Errorf("place %v is not reachable from start", id))
+
+			continue
+		}
+
+		if id != *b.finish && len(b.places[id].rules) == 0 {
+			errs = append(errs, fmt.Errorf("place %v is a dead end", id))
+		}
+	}
+
+	return errs
+}
+
+// reachable returns places reachable from the start place.
+func (b *Builder[T, V]) reachable() map[V]struct{} {
+	reached := map[V]struct{}{*b.start: {}}
+
+	queue := []V{*b.start}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		for _, r := range b.places[id].rules {
+			for _, to := range b.transitions[*r.transition].to {
+				if _, ok := reached[to]; ok {
+					continue
+				}
+
+				reached[to] = struct{}{}
+				queue = append(queue, to)
+			}
+		}
+	}
+
+	return reached
+}
@@ -0,0 +1,112 @@
+package net_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/net"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	var trace []string
+
+	n := net.New[string, string]("order").Start("new").Finish("done")
+	n.Place("new").On("pay").Fire("pay").To("paid").Safe().
+		Enter(func(from *graph.Place[string, string]) error {
+			trace = append(trace, "paid from "+from.ID)
+
+			return nil
+		}).
+		On("ship").Fire("ship").
+		Do(func(_ *graph.Place[string, string], signal string) error {
+			trace = append(trace, "shipping")
+
+			return nil
+		}).
+		To("done")
+	n.Place("new").On("cancel").Fire("cancel").To("done")
+
+	g, err := n.Build()
+	assert.NoError(t, err)
+
+	p, ok := g.Place("paid")
+	assert.True(t, ok)
+	assert.True(t, p.Safe)
+
+	assert.NoError(t, g.StartGraph())
+	assert.ErrorIs(t, g.Act("ship"), graph.ErrSignalIgnored)
+	assert.NoError(t, g.Act("pay"))
+	assert.NoError(t, g.Act("ship"))
+	assert.True(t, g.IsOnFinish())
+	assert.Equal(t, []string{"paid from new", "shipping"}, trace)
+}
+
+func TestBuilder_Build_SharedTransition(t *testing.T) {
+	n := net.New[int, string]("review").Start("draft").Finish("published")
+	n.Place("draft").On(1).Fire("submit").To("review").
+		On(2).Fire("approve").To("published")
+	n.Place("draft").On(2).Fire("approve")
+
+	g, err := n.Build()
+	assert.NoError(t, err)
+
+	assert.NoError(t, g.Act(2))
+	assert.True(t, g.IsOnFinish())
+}
+
+func TestBuilder_Build_FailedTransition(t *testing.T) {
+	errDeclined := errors.New("declined")
+
+	n := net.New[string, string]("order").Start("new").Finish("done")
+	n.Place("new").On("pay").Fire("pay").
+		Do(func(*graph.Place[string, string], string) error {
+			return errDeclined
+		}).
+		To("done")
+
+	g, err := n.Build()
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, g.Act("pay"), errDeclined)
+	assert.Equal(t, "new", g.Current.ID)
+}
+
+func TestBuilder_Build_Errors(t *testing.T) {
+	n := net.New[string, string]("broken").Finish("lost")
+	n.Place("new").On("pay")
+	n.Place("new").On("ship").Fire("ship").To("done")
+	n.Place("new").On("ship").Fire("pay")
+	n.Place("done").On("split").Fire("split").To("a")
+	n.Transition("split").To("b")
+	n.Place("pay")
+
+	_, err := n.Build()
+	assert.ErrorIs(t, err, net.ErrInvalidNet)
+
+	for _, msg := range []string{
+		"start place is not set",
+		"finish place lost is not defined",
+		"pay is both a place and a transition",
+		"place new: signal pay fires no transition",
+		"place new: signal ship fires both ship and pay",
+		"transition pay leads to no place",
+		"transition split leads to several places [a b]",
+	} {
+		assert.ErrorContains(t, err, msg)
+	}
+}
+
+func TestBuilder_Build_Reachability(t *testing.T) {
+	n := net.New[string, string]("stuck").Start("new").Finish("done")
+	n.Place("new").On("pay").Fire("pay").To("paid")
+	n.Place("orphan").On("go").Fire("go").To("done")
+
+	_, err := n.Build()
+	assert.ErrorIs(t, err, net.ErrInvalidNet)
+	assert.ErrorContains(t, err, "place paid is a dead end")
+	assert.ErrorContains(t, err, "place orphan is not reachable from start")
+	assert.ErrorContains(t, err, "place done is not reachable from start")
+}
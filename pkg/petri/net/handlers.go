@@ -0,0 +1,71 @@
+package net
+
+import (
+	"fmt"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+type route[T comparable, V comparable] struct {
+	signal     T
+	transition *graph.Transition[T, V]
+}
+
+type placeHandler[T comparable, V comparable] struct {
+	place *graph.Place[T, V]
+	rules []route[T, V]
+	enter func(from *graph.Place[T, V]) error
+	leave func(to *graph.Place[T, V]) error
+}
+
+func (h *placeHandler[T, V]) HandleIn(from *graph.Place[T, V]) error {
+	if h.enter == nil {
+		return nil
+	}
+
+	return h.enter(from)
+}
+
+func (h *placeHandler[T, V]) HandleOut(to *graph.Place[T, V]) error {
+	if h.leave == nil {
+		return nil
+	}
+
+	return h.leave(to)
+}
+
+func (h *placeHandler[T, V]) ChooseTo(signal T) (*graph.Transition[T, V], error) {
+	for _, r := range h.rules {
+		if r.signal == signal {
+			return r.transition, nil
+		}
+	}
+
+	return nil, fmt.Errorf("place %v, signal %v: %w", h.place.ID, signal, graph.ErrSignalIgnored)
+}
+
+type transitionHandler[T comparable, V comparable] struct {
+	to *graph.Place[T, V]
+	do func(from *graph.Place[T, V], signal T) error
+}
+
+func (h transitionHandler[T, V]) Handle(from *graph.Place[T, V], signal T) (*graph.Place[T, V], error) {
+	if h.do != nil {
+		err := h.do(from, signal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return h.to, nil
+}
+
+type noop struct{}
+
+func (noop) HandleIn() error {
+	return nil
+}
+
+func (noop) HandleOut() error {
+	return nil
+}
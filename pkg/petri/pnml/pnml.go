@@ -0,0 +1,359 @@
+// Package pnml reads and writes graphs in the Petri Net Markup Language.
+//
+// Start, finish and current places and handler names are kept in
+// toolspecific elements of this library. Nets from other tools are read
+// without them: the handler name is the node name, the start place is the only
+// place without incoming arcs and the finish place is the only one without
+// outgoing arcs. A token on another place than the start makes it the current
+// place.
+package pnml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/registry"
+)
+
+const (
+	Namespace = "http://www.pnml.org/version-2009/grammar/pnml"
+	NetType   = "http://www.pnml.org/version-2009/grammar/ptnet"
+	Tool      = "GuePetri"
+	Version   = "1.0"
+)
+
+var ErrInvalidDocument = errors.New("invalid PNML document")
+
+type document struct {
+	XMLName xml.Name `xml:"pnml"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Nets    []net    `xml:"net"`
+}
+
+type net struct {
+	ID    string         `xml:"id,attr"`
+	Type  string         `xml:"type,attr"`
+	Name  *text          `xml:"name,omitempty"`
+	Tools []toolspecific `xml:"toolspecific,omitempty"`
+	Pages []page         `xml:"page"`
+}
+
+type page struct {
+	ID          string       `xml:"id,attr"`
+	Places      []place      `xml:"place"`
+	Transitions []transition `xml:"transition"`
+	Arcs        []arc        `xml:"arc"`
+	Pages       []page       `xml:"page"`
+}
+
+type place struct {
+	ID             string         `xml:"id,attr"`
+	Name           *text          `xml:"name,omitempty"`
+	InitialMarking *text          `xml:"initialMarking,omitempty"`
+	Tools          []toolspecific `xml:"toolspecific,omitempty"`
+}
+
+type transition struct {
+	ID    string         `xml:"id,attr"`
+	Name  *text          `xml:"name,omitempty"`
+	Tools []toolspecific `xml:"toolspecific,omitempty"`
+}
+
+type arc struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type text struct {
+	Text string `xml:"text"`
+}
+
+type toolspecific struct {
+	Tool    string   `xml:"tool,attr"`
+	Version string   `xml:"version,attr"`
+	Handler string   `xml:"handler,omitempty"`
+	Roles   []string `xml:"role,omitempty"`
+	Current string   `xml:"current,omitempty"`
+}
+
+// Write writes the graph as a PNML document.
+func Write[T any, V comparable](w io.Writer, g *graph.Petri[T, V]) error {
+	return Encode(w, registry.Describe(g))
+}
+
+// Encode writes the nets as a PNML document. The current place, or the start
+// place of a graph that is not started, holds the initial marking. The
+// current place is also named in the toolspecific element of the net.
+func Encode(w io.Writer, nets ...registry.Net) error {
+	doc := document{Xmlns: Namespace}
+	for _, n := range nets {
+		doc.Nets = append(doc.Nets, encodeNet(n))
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("writing PNML: %w", err)
+	}
+
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+
+	err = e.Encode(doc)
+	if err != nil {
+		return fmt.Errorf("writing PNML: %w", err)
+	}
+
+	_, err = io.WriteString(w, "\n")
+
+	return err
+}
+
+// Read builds the first net of the document with handlers from the registry.
+func Read[T any](r io.Reader, reg *registry.Registry[T]) (*graph.Petri[T, string], error) {
+	nets, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nets) == 0 {
+		return nil, fmt.Errorf("%w: no net", ErrInvalidDocument)
+	}
+
+	return reg.Build(nets[0])
+}
+
+// Decode reads the nets of a PNML document. Pages are flattened.
+func Decode(r io.Reader) ([]registry.Net, error) {
+	doc := document{}
+
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+
+	result := make([]registry.Net, 0, len(doc.Nets))
+	for _, n := range doc.Nets {
+		decoded, err := decodeNet(n)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, decoded)
+	}
+
+	return result, nil
+}
+
+func encodeNet(n registry.Net) net {
+	marked := n.Current
+	if marked == "" {
+		marked = n.Start
+	}
+
+	pg := page{ID: n.ID + "-page"}
+
+	for _, node := range n.Places {
+		p := place{ID: node.ID, Name: &text{Text: node.ID}}
+
+		var roles []string
+		if node.ID == n.Start {
+			roles = append(roles, "start")
+		}
+
+		if node.ID == n.Finish {
+			roles = append(roles, "finish")
+		}
+
+		p.Tools = tools(node.Handler, roles...)
+
+		if node.ID == marked {
+			p.InitialMarking = &text{Text: "1"}
+		}
+
+		pg.Places = append(pg.Places, p)
+	}
+
+	for _, node := range n.Transitions {
+		pg.Transitions = append(pg.Transitions, transition{ID: node.ID, Name: &text{Text: node.ID}, Tools: tools(node.Handler)})
+	}
+
+	for i, a := range n.Arcs {
+		pg.Arcs = append(pg.Arcs, arc{ID: "a" + strconv.Itoa(i), Source: a.From, Target: a.To})
+	}
+
+	result := net{
+		ID:    n.ID,
+		Type:  NetType,
+		Name:  &text{Text: n.ID},
+		Tools: tools(n.Handler),
+		Pages: []page{pg},
+	}
+
+	result.Tools[0].Current = n.Current
+
+	return result
+}
+
+func tools(handler string, roles ...string) []toolspecific {
+	return []toolspecific{{Tool: Tool, Version: Version, Handler: handler, Roles: roles}}
+}
+
+func decodeNet(n net) (registry.Net, error) {
+	result := registry.Net{ID: n.ID, Handler: handler(n.Name, n.Tools)}
+
+	var (
+		errs     []error
+		starts   []string
+		finishes []string
+		marked   []string
+		total    int
+	)
+
+	incoming := map[string]int{}
+	outgoing := map[string]int{}
+
+	var visit func(pg page)
+	visit = func(pg page) {
+		for _, p := range pg.Places {
+			result.Places = append(result.Places, registry.Node{ID: p.ID, Handler: handler(p.Name, p.Tools)})
+
+			tool, _ := own(p.Tools)
+			for _, role := range tool.Roles {
+				switch role {
+				case "start":
+					starts = append(starts, p.ID)
+				case "finish":
+					finishes = append(finishes, p.ID)
+				}
+			}
+
+			tokens, err := marking(p.InitialMarking)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("place %s: %w", p.ID, err))
+			}
+
+			if tokens > 0 {
+				marked = append(marked, p.ID)
+				total += tokens
+			}
+		}
+
+		for _, t := range pg.Transitions {
+			result.Transitions = append(result.Transitions, registry.Node{ID: t.ID, Handler: handler(t.Name, t.Tools)})
+		}
+
+		for _, a := range pg.Arcs {
+			result.Arcs = append(result.Arcs, registry.Arc{From: a.Source, To: a.Target})
+			outgoing[a.Source]++
+			incoming[a.Target]++
+		}
+
+		for _, nested := range pg.Pages {
+			visit(nested)
+		}
+	}
+
+	for _, pg := range n.Pages {
+		visit(pg)
+	}
+
+	if len(starts) == 0 {
+		starts = unconnected(result.Places, incoming)
+	}
+
+	if len(finishes) == 0 {
+		finishes = unconnected(result.Places, outgoing)
+	}
+
+	if len(starts) != 1 {
+		errs = append(errs, fmt.Errorf("expected one start place, found %v", starts))
+	} else {
+		result.Start = starts[0]
+	}
+
+	if len(finishes) != 1 {
+		errs = append(errs, fmt.Errorf("expected one finish place, found %v", finishes))
+	} else {
+		result.Finish = finishes[0]
+	}
+
+	if total > 1 {
+		errs = append(errs, fmt.Errorf("expected at most one token, found %d on %v", total, marked))
+	}
+
+	// The current place of this library tells a started graph on the start
+	// place from one that is not started, the marking does not.
+	tool, _ := own(n.Tools)
+	switch {
+	case tool.Current != "":
+		result.Current = tool.Current
+	case len(marked) == 1 && marked[0] != result.Start:
+		result.Current = marked[0]
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		return registry.Net{}, fmt.Errorf("net %s: %w: %w", n.ID, ErrInvalidDocument, err)
+	}
+
+	return result, nil
+}
+
+// own returns the toolspecific element of this library.
+func own(tools []toolspecific) (toolspecific, bool) {
+	for _, t := range tools {
+		if t.Tool == Tool {
+			return t, true
+		}
+	}
+
+	return toolspecific{}, false
+}
+
+// handler returns the handler name of a node, which is its name in nets of
+// other tools.
+func handler(name *text, tools []toolspecific) string {
+	if tool, ok := own(tools); ok {
+		return tool.Handler
+	}
+
+	if name == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(name.Text)
+}
+
+func marking(m *text) (int, error) {
+	if m == nil {
+		return 0, nil
+	}
+
+	tokens, err := strconv.Atoi(strings.TrimSpace(m.Text))
+	if err != nil {
+		return 0, fmt.Errorf("initial marking %q: %w", m.Text, err)
+	}
+
+	if tokens < 0 {
+		return 0, fmt.Errorf("initial marking %q is negative", m.Text)
+	}
+
+	return tokens, nil
+}
+
+func unconnected(places []registry.Node, arcs map[string]int) []string {
+	var result []string
+	for _, p := range places {
+		if arcs[p.ID] == 0 {
+			result = append(result, p.ID)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,185 @@
+package pnml_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/pnml"
+	"github.com/uzh13/GuePetri/pkg/petri/registry"
+)
+
+func makeNet() registry.Net {
+	return registry.Net{
+		ID:          "order",
+		Handler:     "orders",
+		Places:      []registry.Node{{ID: "new"}, {ID: "paid", Handler: "audit"}, {ID: "done"}},
+		Transitions: []registry.Node{{ID: "pay"}},
+		Arcs: []registry.Arc{
+			{From: "new", To: "pay"},
+			{From: "pay", To: "paid"},
+		},
+		Start:   "new",
+		Finish:  "done",
+		Current: "paid",
+	}
+}
+
+func TestEncode(t *testing.T) {
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<pnml xmlns="http://www.pnml.org/version-2009/grammar/pnml">
+  <net id="order" type="http://www.pnml.org/version-2009/grammar/ptnet">
+    <name>
+      <text>order</text>
+    </name>
+    <toolspecific tool="GuePetri" version="1.0">
+      <handler>orders</handler>
+      <current>paid</current>
+    </toolspecific>
+    <page id="order-page">
+      <place id="new">
+        <name>
+          <text>new</text>
+        </name>
+        <toolspecific tool="GuePetri" version="1.0">
+          <role>start</role>
+        </toolspecific>
+      </place>
+      <place id="paid">
+        <name>
+          <text>paid</text>
+        </name>
+        <initialMarking>
+          <text>1</text>
+        </initialMarking>
+        <toolspecific tool="GuePetri" version="1.0">
+          <handler>audit</handler>
+        </toolspecific>
+      </place>
+      <place id="done">
+        <name>
+          <text>done</text>
+        </name>
+        <toolspecific tool="GuePetri" version="1.0">
+          <role>finish</role>
+        </toolspecific>
+      </place>
+      <transition id="pay">
+        <name>
+          <text>pay</text>
+        </name>
+        <toolspecific tool="GuePetri" version="1.0"></toolspecific>
+      </transition>
+      <arc id="a0" source="new" target="pay"></arc>
+      <arc id="a1" source="pay" target="paid"></arc>
+    </page>
+  </net>
+</pnml>
+`
+
+	b := bytes.Buffer{}
+	assert.NoError(t, pnml.Encode(&b, makeNet()))
+	assert.Equal(t, expected, b.String())
+
+	nets, err := pnml.Decode(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, []registry.Net{makeNet()}, nets)
+}
+
+func TestWriteRead(t *testing.T) {
+	n := makeNet()
+	n.Current = ""
+	n.Arcs = append(n.Arcs, registry.Arc{From: "paid", To: "ship"}, registry.Arc{From: "ship", To: "done"})
+	n.Transitions = append(n.Transitions, registry.Node{ID: "ship"})
+
+	reg := registry.Default[string]()
+
+	g, err := reg.Build(n)
+	assert.NoError(t, err)
+
+	b := bytes.Buffer{}
+	assert.NoError(t, pnml.Write(&b, g))
+
+	read, err := pnml.Read(&b, reg)
+	assert.NoError(t, err)
+	assert.Nil(t, read.Current)
+
+	assert.NoError(t, read.Act("pay"))
+	assert.NoError(t, read.Act("ship"))
+	assert.True(t, read.IsOnFinish())
+}
+
+func TestEncodeDecode_Roles(t *testing.T) {
+	started := makeNet()
+	started.Current = started.Start
+
+	loop := registry.Net{
+		ID:          "loop",
+		Places:      []registry.Node{{ID: "idle"}},
+		Transitions: []registry.Node{{ID: "tick"}},
+		Arcs:        []registry.Arc{{From: "idle", To: "tick"}, {From: "tick", To: "idle"}},
+		Start:       "idle",
+		Finish:      "idle",
+	}
+
+	b := bytes.Buffer{}
+	assert.NoError(t, pnml.Encode(&b, started, loop))
+	assert.Contains(t, b.String(), "<role>start</role>\n          <role>finish</role>")
+
+	nets, err := pnml.Decode(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, []registry.Net{started, loop}, nets)
+}
+
+func TestDecode_OtherTool(t *testing.T) {
+	doc := `<pnml xmlns="http://www.pnml.org/version-2009/grammar/pnml">
+  <net id="n1" type="http://www.pnml.org/version-2009/grammar/ptnet">
+    <page id="top">
+      <place id="p1"><name><text>intake</text></name></place>
+      <transition id="t1"><name><text>approve</text></name></transition>
+      <page id="nested">
+        <place id="p2"><initialMarking><text>1</text></initialMarking></place>
+      </page>
+      <arc id="a1" source="p1" target="t1"/>
+      <arc id="a2" source="t1" target="p2"/>
+    </page>
+  </net>
+</pnml>`
+
+	nets, err := pnml.Decode(strings.NewReader(doc))
+	assert.NoError(t, err)
+	assert.Equal(t, []registry.Net{{
+		ID:          "n1",
+		Places:      []registry.Node{{ID: "p1", Handler: "intake"}, {ID: "p2"}},
+		Transitions: []registry.Node{{ID: "t1", Handler: "approve"}},
+		Arcs:        []registry.Arc{{From: "p1", To: "t1"}, {From: "t1", To: "p2"}},
+		Start:       "p1",
+		Finish:      "p2",
+		Current:     "p2",
+	}}, nets)
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	doc := `<pnml>
+  <net id="n1">
+    <page id="top">
+      <place id="p1"><initialMarking><text>2000000000</text></initialMarking></place>
+      <place id="p2"><initialMarking><text>-1</text></initialMarking></place>
+    </page>
+  </net>
+</pnml>`
+
+	_, err := pnml.Decode(strings.NewReader(doc))
+	assert.ErrorIs(t, err, pnml.ErrInvalidDocument)
+	assert.ErrorContains(t, err, "expected one start place, found [p1 p2]")
+	assert.ErrorContains(t, err, `place p2: initial marking "-1" is negative`)
+	assert.ErrorContains(t, err, "expected at most one token, found 2000000000 on [p1]")
+
+	_, err = pnml.Read(strings.NewReader(`<pnml></pnml>`), registry.Default[string]())
+	assert.ErrorIs(t, err, pnml.ErrInvalidDocument)
+
+	_, err = pnml.Decode(strings.NewReader(`<pnml>`))
+	assert.ErrorIs(t, err, pnml.ErrInvalidDocument)
+}
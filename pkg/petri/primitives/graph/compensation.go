@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TransitionCompensator is an optional TransitionHandler extension. Compensate
+// undoes side effects of Handle when the firing is rolled back.
+type TransitionCompensator[T any, V comparable] interface {
+	Compensate(from *Place[T, V], signal T) error
+}
+
+// PlaceCompensator is an optional PlaceHandler extension. CompensateOut undoes
+// side effects of HandleOut when the firing is rolled back.
+type PlaceCompensator[T any, V comparable] interface {
+	CompensateOut(to *Place[T, V]) error
+}
+
+// rollback runs compensations in the given order after a failed firing. The
+// marking is already restored by the caller, compensation errors are joined
+// to the firing error.
+func (g *Petri[T, V]) rollback(err error, compensations ...func() error) error {
+	errs := []error{err}
+	for _, compensate := range compensations {
+		cErr := compensate()
+		if cErr != nil {
+			errs = append(errs, fmt.Errorf("graph %v rollback: %w", g.ID, cErr))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (g *Petri[T, V]) compensateTransition(ctx context.Context, t *Transition[T, V], from *Place[T, V], signal T) func() error {
+	return func() error {
+		compensator, ok := t.Handler.(TransitionCompensator[T, V])
+		if !ok {
+			return nil
+		}
+
+		call := Call[T, V]{Stage: StageCompensate, GraphID: g.ID, NodeID: t.ID, Signal: signal}
+		err := g.invoke(ctx, call, func(context.Context) error {
+			return compensator.Compensate(from, signal)
+		})
+		if err != nil {
+			return fmt.Errorf("compensating transition %v: %w", t.ID, err)
+		}
+
+		return nil
+	}
+}
+
+func (g *Petri[T, V]) compensateOut(ctx context.Context, p *Place[T, V], to *Place[T, V], signal T) func() error {
+	return func() error {
+		compensator, ok := p.Handler.(PlaceCompensator[T, V])
+		if !ok {
+			return nil
+		}
+
+		call := Call[T, V]{Stage: StageCompensate, GraphID: g.ID, NodeID: p.ID, Signal: signal}
+		err := g.invoke(ctx, call, func(context.Context) error {
+			return compensator.CompensateOut(to)
+		})
+		if err != nil {
+			return fmt.Errorf("compensating exit from place %v to %v: %w", p.ID, to.ID, err)
+		}
+
+		return nil
+	}
+}
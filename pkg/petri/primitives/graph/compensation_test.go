@@ -0,0 +1,83 @@
+package graph_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+type compensatingPlaceHandle struct {
+	mockPlaceHandle
+	log *[]string
+}
+
+func (m *compensatingPlaceHandle) CompensateOut(to *graph.Place[int, string]) error {
+	*m.log = append(*m.log, "compensate out to "+to.ID)
+
+	return nil
+}
+
+type compensatingTransitionHandler struct {
+	mocktransitionHandler
+	log *[]string
+	err error
+}
+
+func (m *compensatingTransitionHandler) Compensate(from *graph.Place[int, string], _ int) error {
+	*m.log = append(*m.log, "compensate transition from "+from.ID)
+
+	return m.err
+}
+
+func makeCompensatingGraph(log *[]string, inErr, outErr, compensateErr error) *graph.Petri[int, string] {
+	startHandler := &compensatingPlaceHandle{mockPlaceHandle: mockPlaceHandle{outErr: outErr}, log: log}
+	startPlace := graph.NewPlace[int, string]("start", startHandler)
+	nextPlace := graph.NewPlace[int, string]("next", &mockPlaceHandle{inErr: inErr})
+
+	transition := graph.NewTransition[int, string]("move", &compensatingTransitionHandler{
+		mocktransitionHandler: mocktransitionHandler{result: nextPlace},
+		log:                   log,
+		err:                   compensateErr,
+	}).AddTo(nextPlace)
+
+	startHandler.choose = transition
+	startPlace.AddTransition(transition)
+
+	return graph.NewPetri[int, string]("testGraph", &mockHandler{}).
+		SetStartPlace(startPlace).
+		SetCurrentPlace(startPlace).
+		SetFinishPlace(nextPlace)
+}
+
+func TestPetri_Act_RollbackOnHandleIn(t *testing.T) {
+	var log []string
+	petri := makeCompensatingGraph(&log, errors.New("in failed"), nil, nil)
+
+	err := petri.Act(1)
+	assert.ErrorContains(t, err, "in failed")
+	assert.Equal(t, "start", petri.Current.ID)
+	assert.Equal(t, []string{"compensate out to next", "compensate transition from start"}, log)
+}
+
+func TestPetri_Act_RollbackOnHandleOut(t *testing.T) {
+	var log []string
+	petri := makeCompensatingGraph(&log, nil, errors.New("out failed"), errors.New("compensation failed"))
+
+	err := petri.Act(1)
+	assert.ErrorContains(t, err, "out failed")
+	assert.ErrorContains(t, err, "compensation failed")
+	assert.Equal(t, "start", petri.Current.ID)
+	assert.Equal(t, []string{"compensate transition from start"}, log)
+}
+
+func TestPetri_Act_Commit(t *testing.T) {
+	var log []string
+	petri := makeCompensatingGraph(&log, nil, nil, nil)
+
+	err := petri.Act(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "next", petri.Current.ID)
+	assert.Empty(t, log)
+}
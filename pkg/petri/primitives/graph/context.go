@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"context"
+)
+
+// ContextPetriHandler is a context-aware variant of PetriHandler. The graph
+// prefers it when the handler implements both.
+type ContextPetriHandler interface {
+	HandleInContext(ctx context.Context) error
+	HandleOutContext(ctx context.Context) error
+}
+
+// ContextPlaceHandler is a context-aware variant of PlaceHandler. The graph
+// prefers it when the handler implements both.
+type ContextPlaceHandler[T any, V comparable] interface {
+	HandleInContext(ctx context.Context, from *Place[T, V]) error
+	HandleOutContext(ctx context.Context, to *Place[T, V]) error
+	ChooseToContext(ctx context.Context, signal T) (*Transition[T, V], error)
+}
+
+// ContextTransitionHandler is a context-aware variant of TransitionHandler.
+// The graph prefers it when the handler implements both.
+type ContextTransitionHandler[T any, V comparable] interface {
+	HandleContext(ctx context.Context, from *Place[T, V], signal T) (*Place[T, V], error)
+}
+
+// AdaptPetriHandler turns a context-aware handler into a PetriHandler, so it
+// can be used as Petri.Handler. Calls without context get context.Background.
+func AdaptPetriHandler(h ContextPetriHandler) PetriHandler {
+	return petriAdapter{h}
+}
+
+// AdaptPlaceHandler turns a context-aware handler into a PlaceHandler, so it
+// can be used as Place.Handler. Calls without context get context.Background.
+func AdaptPlaceHandler[T any, V comparable](h ContextPlaceHandler[T, V]) PlaceHandler[T, V] {
+	return placeAdapter[T, V]{h}
+}
+
+// AdaptTransitionHandler turns a context-aware handler into a
+// TransitionHandler, so it can be used as Transition.Handler. Calls without
+// context get context.Background.
+func AdaptTransitionHandler[T any, V comparable](h ContextTransitionHandler[T, V]) TransitionHandler[T, V] {
+	return transitionAdapter[T, V]{h}
+}
+
+// PlaceContextOf returns the context-aware form of a place handler: the
+// handler itself when it implements ContextPlaceHandler, otherwise its plain
+// methods with the context dropped. It dispatches the way the graph does, for
+// handlers wrapping other handlers.
+func PlaceContextOf[T any, V comparable](h PlaceHandler[T, V]) ContextPlaceHandler[T, V] {
+	if c, ok := h.(ContextPlaceHandler[T, V]); ok {
+		return c
+	}
+
+	return plainPlace[T, V]{h}
+}
+
+type plainPlace[T any, V comparable] struct {
+	PlaceHandler[T, V]
+}
+
+func (p plainPlace[T, V]) HandleInContext(_ context.Context, from *Place[T, V]) error {
+	return p.HandleIn(from)
+}
+
+func (p plainPlace[T, V]) HandleOutContext(_ context.Context, to *Place[T, V]) error {
+	return p.HandleOut(to)
+}
+
+func (p plainPlace[T, V]) ChooseToContext(_ context.Context, signal T) (*Transition[T, V], error) {
+	return p.ChooseTo(signal)
+}
+
+type petriAdapter struct {
+	ContextPetriHandler
+}
+
+func (a petriAdapter) HandleIn() error {
+	return a.HandleInContext(context.Background())
+}
+
+func (a petriAdapter) HandleOut() error {
+	return a.HandleOutContext(context.Background())
+}
+
+type placeAdapter[T any, V comparable] struct {
+	ContextPlaceHandler[T, V]
+}
+
+func (a placeAdapter[T, V]) HandleIn(from *Place[T, V]) error {
+	return a.HandleInContext(context.Background(), from)
+}
+
+func (a placeAdapter[T, V]) HandleOut(to *Place[T, V]) error {
+	return a.HandleOutContext(context.Background(), to)
+}
+
+func (a placeAdapter[T, V]) ChooseTo(signal T) (*Transition[T, V], error) {
+	return a.ChooseToContext(context.Background(), signal)
+}
+
+type transitionAdapter[T any, V comparable] struct {
+	ContextTransitionHandler[T, V]
+}
+
+func (a transitionAdapter[T, V]) Handle(from *Place[T, V], signal T) (*Place[T, V], error) {
+	return a.HandleContext(context.Background(), from, signal)
+}
+
+func graphHandleIn(ctx context.Context, h PetriHandler) error {
+	if c, ok := h.(ContextPetriHandler); ok {
+		return c.HandleInContext(ctx)
+	}
+
+	return h.HandleIn()
+}
+
+func graphHandleOut(ctx context.Context, h PetriHandler) error {
+	if c, ok := h.(ContextPetriHandler); ok {
+		return c.HandleOutContext(ctx)
+	}
+
+	return h.HandleOut()
+}
+
+func placeHandleIn[T any, V comparable](ctx context.Context, p *Place[T, V], from *Place[T, V]) error {
+	if c, ok := p.Handler.(ContextPlaceHandler[T, V]); ok {
+		return c.HandleInContext(ctx, from)
+	}
+
+	return p.Handler.HandleIn(from)
+}
+
+func placeHandleOut[T any, V comparable](ctx context.Context, p *Place[T, V], to *Place[T, V]) error {
+	if c, ok := p.Handler.(ContextPlaceHandler[T, V]); ok {
+		return c.HandleOutContext(ctx, to)
+	}
+
+	return p.Handler.HandleOut(to)
+}
+
+func placeChooseTo[T any, V comparable](ctx context.Context, p *Place[T, V], signal T) (*Transition[T, V], error) {
+	if c, ok := p.Handler.(ContextPlaceHandler[T, V]); ok {
+		return c.ChooseToContext(ctx, signal)
+	}
+
+	return p.Handler.ChooseTo(signal)
+}
+
+func transitionHandle[T any, V comparable](ctx context.Context, t *Transition[T, V], from *Place[T, V], signal T) (*Place[T, V], error) {
+	if c, ok := t.Handler.(ContextTransitionHandler[T, V]); ok {
+		return c.HandleContext(ctx, from, signal)
+	}
+
+	return t.Handler.Handle(from, signal)
+}
@@ -0,0 +1,164 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+type ctxKey struct{}
+
+type ctxRecorder struct {
+	seen []string
+}
+
+func (r *ctxRecorder) record(ctx context.Context, stage string) {
+	value, _ := ctx.Value(ctxKey{}).(string)
+	r.seen = append(r.seen, stage+":"+value)
+}
+
+type ctxPetriHandler struct {
+	rec *ctxRecorder
+}
+
+func (h *ctxPetriHandler) HandleInContext(ctx context.Context) error {
+	h.rec.record(ctx, "graph in")
+
+	return nil
+}
+
+func (h *ctxPetriHandler) HandleOutContext(ctx context.Context) error {
+	h.rec.record(ctx, "graph out")
+
+	return nil
+}
+
+type ctxPlaceHandler struct {
+	rec    *ctxRecorder
+	choose *graph.Transition[int, string]
+}
+
+func (h *ctxPlaceHandler) HandleInContext(ctx context.Context, _ *graph.Place[int, string]) error {
+	h.rec.record(ctx, "place in")
+
+	return nil
+}
+
+func (h *ctxPlaceHandler) HandleOutContext(ctx context.Context, _ *graph.Place[int, string]) error {
+	h.rec.record(ctx, "place out")
+
+	return nil
+}
+
+func (h *ctxPlaceHandler) ChooseToContext(ctx context.Context, _ int) (*graph.Transition[int, string], error) {
+	h.rec.record(ctx, "choose")
+
+	return h.choose, nil
+}
+
+type ctxTransitionHandler struct {
+	rec  *ctxRecorder
+	next *graph.Place[int, string]
+}
+
+func (h *ctxTransitionHandler) HandleContext(ctx context.Context, _ *graph.Place[int, string], _ int) (*graph.Place[int, string], error) {
+	h.rec.record(ctx, "transition")
+
+	return h.next, nil
+}
+
+func makeContextGraph(rec *ctxRecorder) *graph.Petri[int, string] {
+	finish := graph.NewPlace[int, string]("finish", graph.AdaptPlaceHandler[int, string](&ctxPlaceHandler{rec: rec}))
+	transition := graph.NewTransition[int, string]("move", graph.AdaptTransitionHandler[int, string](
+		&ctxTransitionHandler{rec: rec, next: finish},
+	)).AddTo(finish)
+	start := graph.NewPlace[int, string]("start", graph.AdaptPlaceHandler[int, string](
+		&ctxPlaceHandler{rec: rec, choose: transition},
+	)).AddTransition(transition)
+
+	return graph.NewPetri[int, string]("ctx", graph.AdaptPetriHandler(&ctxPetriHandler{rec: rec})).
+		SetStartPlace(start).
+		SetFinishPlace(finish)
+}
+
+func TestPetri_ActContext(t *testing.T) {
+	rec := &ctxRecorder{}
+	petri := makeContextGraph(rec)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "trace")
+
+	assert.NoError(t, petri.ActContext(ctx, 1))
+	assert.NoError(t, petri.FinishGraphContext(ctx))
+	assert.Equal(t, []string{
+		"graph in:trace",
+		"place in:trace",
+		"choose:trace",
+		"transition:trace",
+		"place out:trace",
+		"place in:trace",
+		"place out:trace",
+		"graph out:trace",
+	}, rec.seen)
+}
+
+func TestPetri_ActContext_Cancelled(t *testing.T) {
+	rec := &ctxRecorder{}
+	petri := makeContextGraph(rec)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := petri.ActContext(ctx, 1)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Empty(t, rec.seen)
+}
+
+func TestPetri_Act_ContextHandlerWithoutContext(t *testing.T) {
+	rec := &ctxRecorder{}
+	petri := makeContextGraph(rec)
+
+	assert.NoError(t, petri.Act(1))
+	assert.Equal(t, "finish", petri.Current.ID)
+	assert.Equal(t, "graph in:", rec.seen[0])
+}
+
+func TestPetri_SuspendResumeContext(t *testing.T) {
+	var log []string
+	startPlace := &graph.Place[int, string]{ID: "start", Handler: &mockPlaceHandle{}}
+
+	petri := &graph.Petri[int, string]{
+		ID:      "testGraph",
+		Start:   startPlace,
+		Current: startPlace,
+		Handler: &mockSuspendHandler{},
+	}
+
+	ctx := graph.WithInterceptors(context.Background(), recordingInterceptor("ctx", &log))
+
+	assert.NoError(t, petri.SuspendContext(ctx))
+	assert.NoError(t, petri.ResumeContext(ctx))
+	assert.Equal(t, []string{"ctx graph_suspend testGraph", "ctx graph_resume testGraph"}, log)
+}
+
+func TestPlaceContextOf(t *testing.T) {
+	rec := &ctxRecorder{}
+	aware := graph.PlaceContextOf(graph.AdaptPlaceHandler[int, string](&ctxPlaceHandler{rec: rec}))
+	traced := context.WithValue(context.Background(), ctxKey{}, "trace")
+
+	assert.NoError(t, aware.HandleInContext(traced, nil))
+	assert.Equal(t, []string{"place in:trace"}, rec.seen)
+
+	inErr := errors.New("in")
+	outErr := errors.New("out")
+	choose := graph.NewTransition[int, string]("move", &mocktransitionHandler{})
+	plain := graph.PlaceContextOf[int, string](&mockPlaceHandle{inErr: inErr, outErr: outErr, choose: choose})
+	ctx := context.Background()
+
+	assert.ErrorIs(t, plain.HandleInContext(ctx, nil), inErr)
+	assert.ErrorIs(t, plain.HandleOutContext(ctx, nil), outErr)
+
+	transition, err := plain.ChooseToContext(ctx, 1)
+	assert.NoError(t, err)
+	assert.Same(t, choose, transition)
+}
@@ -0,0 +1,34 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSignalIgnored is returned by PlaceHandler.ChooseTo when the place does
+	// not handle the signal. The graph stays on its place.
+	ErrSignalIgnored       = errors.New("signal ignored")
+	ErrForbiddenTransition = errors.New("forbidden transition")
+	ErrForbiddenPlace      = errors.New("forbidden place")
+	ErrNotStarted          = errors.New("graph is not started")
+	ErrUnknownNode         = errors.New("unknown node")
+	ErrPanic               = errors.New("handler panicked")
+)
+
+// HandlerError is a failure of a handler call. NodeID is the place or the
+// transition of the call, for graph stages it is the graph ID.
+type HandlerError[V comparable] struct {
+	Stage   Stage
+	GraphID V
+	NodeID  V
+	Err     error
+}
+
+func (e *HandlerError[V]) Error() string {
+	return fmt.Sprintf("%v of graph %v, node %v: %v", e.Stage, e.GraphID, e.NodeID, e.Err)
+}
+
+func (e *HandlerError[V]) Unwrap() error {
+	return e.Err
+}
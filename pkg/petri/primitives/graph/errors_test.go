@@ -0,0 +1,64 @@
+package graph_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+func TestPetri_Act_ForbiddenTransition(t *testing.T) {
+	placeHandler := &mockPlaceHandle{}
+	start := graph.NewPlace[int, string]("start", placeHandler)
+	placeHandler.choose = graph.NewTransition[int, string]("stranger", &mocktransitionHandler{})
+
+	petri := graph.NewPetri[int, string]("testGraph", &mockHandler{}).
+		SetStartPlace(start).
+		SetCurrentPlace(start)
+
+	assert.ErrorIs(t, petri.Act(1), graph.ErrForbiddenTransition)
+}
+
+func TestPetri_Act_ForbiddenPlace(t *testing.T) {
+	placeHandler := &mockPlaceHandle{}
+	start := graph.NewPlace[int, string]("start", placeHandler)
+	stranger := graph.NewPlace[int, string]("stranger", &mockPlaceHandle{})
+	transition := graph.NewTransition[int, string]("t", &mocktransitionHandler{result: stranger})
+	placeHandler.choose = transition
+	start.AddTransition(transition)
+
+	petri := graph.NewPetri[int, string]("testGraph", &mockHandler{}).
+		SetStartPlace(start).
+		SetCurrentPlace(start)
+
+	assert.ErrorIs(t, petri.Act(1), graph.ErrForbiddenPlace)
+	assert.Equal(t, "start", petri.Current.ID)
+}
+
+func TestPetri_FinishGraph_NotStarted(t *testing.T) {
+	petri := graph.NewPetri[int, string]("testGraph", &mockHandler{})
+
+	assert.ErrorIs(t, petri.FinishGraph(), graph.ErrNotStarted)
+	assert.False(t, petri.IsOnStart())
+	assert.False(t, petri.IsOnFinish())
+}
+
+func TestPetri_Act_HandlerError(t *testing.T) {
+	cause := errors.New("storage down")
+
+	start := graph.NewPlace[int, string]("start", &mockPlaceHandle{chooseErr: cause})
+
+	petri := graph.NewPetri[int, string]("testGraph", &mockHandler{}).
+		SetStartPlace(start).
+		SetCurrentPlace(start)
+
+	err := petri.Act(1)
+	assert.ErrorIs(t, err, cause)
+
+	var handlerErr *graph.HandlerError[string]
+	assert.True(t, errors.As(err, &handlerErr))
+	assert.Equal(t, graph.StageChooseTo, handlerErr.Stage)
+	assert.Equal(t, "testGraph", handlerErr.GraphID)
+	assert.Equal(t, "start", handlerErr.NodeID)
+}
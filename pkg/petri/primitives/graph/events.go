@@ -0,0 +1,295 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventKind names a state change of a graph.
+type EventKind int
+
+const (
+	EventGraphAdded EventKind = iota
+	EventGraphStarted
+	EventTransitionFired
+	EventPlaceEntered
+	EventPlaceLeft
+	EventGraphPreempted
+	EventGraphFinished
+	EventGraphFailed
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventGraphAdded:
+		return "graph_added"
+	case EventGraphStarted:
+		return "graph_started"
+	case EventTransitionFired:
+		return "transition_fired"
+	case EventPlaceEntered:
+		return "place_entered"
+	case EventPlaceLeft:
+		return "place_left"
+	case EventGraphPreempted:
+		return "graph_preempted"
+	case EventGraphFinished:
+		return "graph_finished"
+	case EventGraphFailed:
+		return "graph_failed"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+}
+
+// Event is a state change of a graph. Place is set for place events and for
+// the place a graph was on when it was started, finished or failed. Transition,
+// From and To are set for fired transitions, To of a preempted graph is the
+// graph taking focus. Level is set by PetriQueue.
+type Event[T any, V comparable] struct {
+	Kind       EventKind
+	At         time.Time
+	GraphID    V
+	Place      V
+	Transition V
+	From       V
+	To         V
+	Signal     T
+	Level      int
+	Err        error
+}
+
+// OverflowPolicy decides what a channel subscription does with an event when
+// its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowDrop discards the event.
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock waits for the reader, holding up the graph publishing it.
+	OverflowBlock
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDrop:
+		return "drop"
+	case OverflowBlock:
+		return "block"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// Bus delivers events to subscribers in the order they subscribed. The zero
+// value is ready to use.
+type Bus[T any, V comparable] struct {
+	mu   sync.Mutex
+	next int
+	subs []subscription[T, V]
+}
+
+type subscription[T any, V comparable] struct {
+	id      int
+	deliver func(Event[T, V])
+}
+
+// Subscribe calls fn synchronously for every event until the returned cancel
+// function is called.
+func (b *Bus[T, V]) Subscribe(fn func(Event[T, V])) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.subs = append(b.subs, subscription[T, V]{id: id, deliver: fn})
+
+	return func() {
+		b.unsubscribe(id)
+	}
+}
+
+// SubscribeChan delivers events to a channel with the given buffer. The
+// channel is closed by the returned cancel function.
+func (b *Bus[T, V]) SubscribeChan(size int, policy OverflowPolicy) (<-chan Event[T, V], func()) {
+	c := &channel[T, V]{
+		events: make(chan Event[T, V], size),
+		done:   make(chan struct{}),
+		policy: policy,
+	}
+
+	unsubscribe := b.Subscribe(c.send)
+
+	return c.events, func() {
+		unsubscribe()
+		c.close()
+	}
+}
+
+// Publish delivers the event to every subscriber.
+func (b *Bus[T, V]) Publish(e Event[T, V]) {
+	b.mu.Lock()
+	subs := append([]subscription[T, V](nil), b.subs...)
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.deliver(e)
+	}
+}
+
+func (b *Bus[T, V]) active() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subs) != 0
+}
+
+func (b *Bus[T, V]) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, sub := range b.subs {
+		if sub.id == id {
+			b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+
+			return
+		}
+	}
+}
+
+type channel[T any, V comparable] struct {
+	mu     sync.Mutex
+	once   sync.Once
+	closed bool
+	events chan Event[T, V]
+	done   chan struct{}
+	policy OverflowPolicy
+}
+
+func (c *channel[T, V]) send(e Event[T, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	if c.policy == OverflowBlock {
+		select {
+		case c.events <- e:
+		case <-c.done:
+		}
+
+		return
+	}
+
+	select {
+	case c.events <- e:
+	default:
+	}
+}
+
+func (c *channel[T, V]) close() {
+	c.once.Do(func() {
+		close(c.done)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.closed = true
+		close(c.events)
+	})
+}
+
+type sinkKey[T any, V comparable] struct{}
+
+// WithEventSink returns a context passing the events of every graph acting
+// with it to sink, in addition to the graph's own subscribers.
+func WithEventSink[T any, V comparable](ctx context.Context, sink func(Event[T, V])) context.Context {
+	return context.WithValue(ctx, sinkKey[T, V]{}, sink)
+}
+
+// Subscribe calls fn synchronously for every event of the graph until the
+// returned cancel function is called.
+func (g *Petri[T, V]) Subscribe(fn func(Event[T, V])) func() {
+	return g.bus().Subscribe(fn)
+}
+
+// SubscribeChan delivers events of the graph to a buffered channel.
+func (g *Petri[T, V]) SubscribeChan(size int, policy OverflowPolicy) (<-chan Event[T, V], func()) {
+	return g.bus().SubscribeChan(size, policy)
+}
+
+func (g *Petri[T, V]) bus() *Bus[T, V] {
+	if g.events == nil {
+		g.events = &Bus[T, V]{}
+	}
+
+	return g.events
+}
+
+// emit publishes the event built by fn. fn is not called without subscribers.
+func (g *Petri[T, V]) emit(ctx context.Context, fn func() Event[T, V]) {
+	sink, _ := ctx.Value(sinkKey[T, V]{}).(func(Event[T, V]))
+	if sink == nil && !g.events.active() {
+		return
+	}
+
+	e := fn()
+	e.At = time.Now()
+	e.GraphID = g.ID
+
+	if g.events != nil {
+		g.events.Publish(e)
+	}
+
+	if sink != nil {
+		sink(e)
+	}
+}
+
+func (g *Petri[T, V]) emitLifecycle(ctx context.Context, kind EventKind, err error) {
+	g.emit(ctx, func() Event[T, V] {
+		e := Event[T, V]{Kind: kind, Err: err}
+		if err != nil {
+			e.Kind = EventGraphFailed
+		}
+
+		if g.Current != nil {
+			e.Place = g.Current.ID
+		}
+
+		return e
+	})
+}
+
+func (g *Petri[T, V]) emitFiring(ctx context.Context, entry HistoryEntry[T, V], err error) {
+	if errors.Is(err, ErrSignalIgnored) {
+		return
+	}
+
+	if err != nil {
+		g.emit(ctx, func() Event[T, V] {
+			return Event[T, V]{Kind: EventGraphFailed, Place: entry.From, Transition: entry.Transition, Signal: entry.Signal, Err: err}
+		})
+
+		return
+	}
+
+	g.emit(ctx, func() Event[T, V] {
+		return Event[T, V]{Kind: EventPlaceLeft, Place: entry.From, Signal: entry.Signal}
+	})
+	g.emit(ctx, func() Event[T, V] {
+		return Event[T, V]{Kind: EventTransitionFired, Transition: entry.Transition, From: entry.From, To: entry.To, Signal: entry.Signal}
+	})
+	g.emit(ctx, func() Event[T, V] {
+		return Event[T, V]{Kind: EventPlaceEntered, Place: entry.To, Signal: entry.Signal}
+	})
+}
@@ -0,0 +1,116 @@
+package graph_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+func kinds(events []graph.Event[int, string]) []string {
+	result := make([]string, 0, len(events))
+	for _, e := range events {
+		result = append(result, e.Kind.String()+" "+e.Place+e.Transition)
+	}
+
+	return result
+}
+
+func TestPetri_Subscribe(t *testing.T) {
+	var events []graph.Event[int, string]
+
+	petri := makeLoggedGraph()
+	cancel := petri.Subscribe(func(e graph.Event[int, string]) {
+		events = append(events, e)
+	})
+
+	assert.NoError(t, petri.Act(7))
+	assert.NoError(t, petri.FinishGraph())
+	assert.Equal(t, []string{
+		"graph_started start",
+		"place_entered start",
+		"place_left start",
+		"transition_fired go",
+		"place_entered finish",
+		"place_left finish",
+		"graph_finished finish",
+	}, kinds(events))
+
+	fired := events[3]
+	assert.Equal(t, "testGraph", fired.GraphID)
+	assert.Equal(t, "start", fired.From)
+	assert.Equal(t, "finish", fired.To)
+	assert.Equal(t, 7, fired.Signal)
+	assert.False(t, fired.At.IsZero())
+
+	cancel()
+	assert.NoError(t, petri.StartGraph())
+	assert.Len(t, events, 7)
+}
+
+func TestPetri_Subscribe_Failed(t *testing.T) {
+	var events []graph.Event[int, string]
+
+	petri := makeLoggedGraph()
+	petri.Finish.Handler = &mockPlaceHandle{inErr: errors.New("closed")}
+	petri.Subscribe(func(e graph.Event[int, string]) {
+		events = append(events, e)
+	})
+
+	assert.Error(t, petri.Act(7))
+	assert.Equal(t, []string{
+		"graph_started start",
+		"place_entered start",
+		"graph_failed startgo",
+	}, kinds(events))
+	assert.Error(t, events[2].Err)
+}
+
+func TestPetri_SubscribeChan_Drop(t *testing.T) {
+	petri := makeLoggedGraph()
+	events, cancel := petri.SubscribeChan(1, graph.OverflowDrop)
+
+	assert.NoError(t, petri.Act(7))
+	cancel()
+	cancel()
+
+	var received []graph.Event[int, string]
+	for e := range events {
+		received = append(received, e)
+	}
+
+	assert.Equal(t, []string{"graph_started start"}, kinds(received))
+}
+
+func TestPetri_SubscribeChan_Block(t *testing.T) {
+	petri := makeLoggedGraph()
+	events, cancel := petri.SubscribeChan(0, graph.OverflowBlock)
+
+	done := make(chan error)
+	go func() {
+		done <- petri.Act(7)
+	}()
+
+	var received []graph.Event[int, string]
+	for i := 0; i < 5; i++ {
+		received = append(received, <-events)
+	}
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, []string{
+		"graph_started start",
+		"place_entered start",
+		"place_left start",
+		"transition_fired go",
+		"place_entered finish",
+	}, kinds(received))
+
+	go func() {
+		done <- petri.FinishGraph()
+	}()
+
+	<-events
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -1,7 +1,10 @@
 package graph
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"time"
 )
 
 type PetriHandler interface {
@@ -9,12 +12,39 @@ type PetriHandler interface {
 	HandleOut() error
 }
 
+// CancelHandler is an optional PetriHandler extension. It is called instead of
+// HandleOut when a started graph is cancelled before reaching its finish place.
+type CancelHandler interface {
+	HandleCancel() error
+}
+
+// SuspendHandler is an optional PetriHandler extension notified when a started
+// graph loses focus to a higher priority graph and when it gets focus back.
+type SuspendHandler interface {
+	Suspend() error
+	Resume() error
+}
+
 type Petri[T any, V comparable] struct {
-	ID      V            `json:"id"`
-	Start   *Place[T, V] `json:"start"`
-	Finish  *Place[T, V] `json:"finish"`
-	Current *Place[T, V] `json:"current"`
-	Handler PetriHandler
+	ID        V            `json:"id"`
+	Start     *Place[T, V] `json:"start"`
+	Finish    *Place[T, V] `json:"finish"`
+	Current   *Place[T, V] `json:"current"`
+	Suspended bool         `json:"suspended,omitempty"`
+	// Mailbox keeps signals addressed to the graph while it has no focus
+	Mailbox []T `json:"mailbox,omitempty"`
+	// Fired keeps committed firings for compensation on Abort
+	Fired []Firing[T, V] `json:"fired,omitempty"`
+	// History is the audit trail of firing attempts, capped by Retention
+	History []HistoryEntry[T, V] `json:"history,omitempty"`
+	// Retention caps History, DefaultHistoryRetention applies while it is zero
+	Retention HistoryRetention `json:"retention"`
+	// Handler is code, not state, so it is left out of the JSON form and
+	// set again when a graph is loaded
+	Handler      PetriHandler `json:"-"`
+	interceptors []Interceptor[T, V]
+	logger       *slog.Logger
+	events       *Bus[T, V]
 }
 
 func NewPetri[T any, V comparable](id V, handler PetriHandler) *Petri[T, V] {
@@ -43,13 +73,31 @@ func (g *Petri[T, V]) SetCurrentPlace(n *Place[T, V]) *Petri[T, V] {
 }
 
 func (g *Petri[T, V]) StartGraph() error {
-	err := g.Handler.HandleIn()
+	return g.StartGraphContext(context.Background())
+}
+
+func (g *Petri[T, V]) StartGraphContext(ctx context.Context) error {
+	err := g.start(ctx)
+	g.logLifecycle(ctx, "graph started", err)
+	g.emitLifecycle(ctx, EventGraphStarted, err)
+
+	if err == nil {
+		g.emit(ctx, func() Event[T, V] {
+			return Event[T, V]{Kind: EventPlaceEntered, Place: g.Current.ID}
+		})
+	}
+
+	return err
+}
+
+func (g *Petri[T, V]) start(ctx context.Context) error {
+	err := g.graphHandleIn(ctx)
 	if err != nil {
 		return fmt.Errorf("starting graph %v: %w", g.ID, err)
 	}
 
 	g.Current = g.Start
-	err = g.Current.Handler.HandleIn(nil)
+	err = g.placeHandleIn(ctx, g.Current, nil, zero[T]())
 	if err != nil {
 		return fmt.Errorf("starting graph %v first place %v: %w", g.ID, g.Current.ID, err)
 	}
@@ -58,16 +106,39 @@ func (g *Petri[T, V]) StartGraph() error {
 }
 
 func (g *Petri[T, V]) IsOnStart() bool {
-	return g.Start.ID == g.Current.ID
+	return g.Current != nil && g.Start.ID == g.Current.ID
 }
 
 func (g *Petri[T, V]) FinishGraph() error {
-	err := g.Current.Handler.HandleOut(nil)
+	return g.FinishGraphContext(context.Background())
+}
+
+func (g *Petri[T, V]) FinishGraphContext(ctx context.Context) error {
+	err := g.finish(ctx)
+	g.logLifecycle(ctx, "graph finished", err)
+
+	if err == nil {
+		g.emit(ctx, func() Event[T, V] {
+			return Event[T, V]{Kind: EventPlaceLeft, Place: g.Current.ID}
+		})
+	}
+
+	g.emitLifecycle(ctx, EventGraphFinished, err)
+
+	return err
+}
+
+func (g *Petri[T, V]) finish(ctx context.Context) error {
+	if g.Current == nil {
+		return fmt.Errorf("finishing graph %v: %w", g.ID, ErrNotStarted)
+	}
+
+	err := g.placeHandleOut(ctx, g.Current, nil, zero[T]())
 	if err != nil {
 		return fmt.Errorf("finishing graph %v last place %v: %w", g.ID, g.Current.ID, err)
 	}
 
-	err = g.Handler.HandleOut()
+	err = g.graphHandleOut(ctx)
 	if err != nil {
 		return fmt.Errorf("finishing graph %v: %w", g.ID, err)
 	}
@@ -75,51 +146,178 @@ func (g *Petri[T, V]) FinishGraph() error {
 	return nil
 }
 
+func (g *Petri[T, V]) Suspend() error {
+	return g.SuspendContext(context.Background())
+}
+
+func (g *Petri[T, V]) SuspendContext(ctx context.Context) error {
+	if g.Current == nil || g.Suspended {
+		return nil
+	}
+
+	suspender, ok := g.Handler.(SuspendHandler)
+	if ok {
+		err := g.invoke(ctx, g.call(StageGraphSuspend), func(context.Context) error {
+			return suspender.Suspend()
+		})
+		if err != nil {
+			return fmt.Errorf("suspending graph %v at place %v: %w", g.ID, g.Current.ID, err)
+		}
+	}
+
+	g.Suspended = true
+
+	return nil
+}
+
+func (g *Petri[T, V]) Resume() error {
+	return g.ResumeContext(context.Background())
+}
+
+func (g *Petri[T, V]) ResumeContext(ctx context.Context) error {
+	if !g.Suspended {
+		return nil
+	}
+
+	suspender, ok := g.Handler.(SuspendHandler)
+	if ok {
+		err := g.invoke(ctx, g.call(StageGraphResume), func(context.Context) error {
+			return suspender.Resume()
+		})
+		if err != nil {
+			return fmt.Errorf("resuming graph %v at place %v: %w", g.ID, g.Current.ID, err)
+		}
+	}
+
+	g.Suspended = false
+
+	return nil
+}
+
+func (g *Petri[T, V]) CancelGraph() error {
+	return g.CancelGraphContext(context.Background())
+}
+
+func (g *Petri[T, V]) CancelGraphContext(ctx context.Context) error {
+	if g.Current == nil {
+		return nil
+	}
+
+	err := g.placeHandleOut(ctx, g.Current, nil, zero[T]())
+	if err != nil {
+		return fmt.Errorf("cancelling graph %v place %v: %w", g.ID, g.Current.ID, err)
+	}
+
+	canceler, ok := g.Handler.(CancelHandler)
+	if !ok {
+		err = g.graphHandleOut(ctx)
+	} else {
+		err = g.invoke(ctx, g.call(StageGraphCancel), func(context.Context) error {
+			return canceler.HandleCancel()
+		})
+	}
+
+	if err != nil {
+		return fmt.Errorf("cancelling graph %v: %w", g.ID, err)
+	}
+
+	return nil
+}
+
 func (g *Petri[T, V]) IsOnFinish() bool {
-	return g.Finish.ID == g.Current.ID
+	return g.Current != nil && g.Finish.ID == g.Current.ID
 }
 
 func (g *Petri[T, V]) Act(signal T) error {
+	return g.ActContext(context.Background(), signal)
+}
+
+func (g *Petri[T, V]) ActContext(ctx context.Context, signal T) error {
+	err := ctx.Err()
+	if err != nil {
+		return fmt.Errorf("graph %v acting on signal %v: %w", g.ID, signal, err)
+	}
+
 	current := g.Current
 	if current == nil {
-		err := g.StartGraph()
+		err := g.StartGraphContext(ctx)
 		if err != nil {
 			return fmt.Errorf("auto starting graph : %w", err)
 		}
 	}
 
-	transition, err := g.Current.Handler.ChooseTo(signal)
+	entry := HistoryEntry[T, V]{At: time.Now(), Signal: signal, From: g.Current.ID}
+	err = g.fire(ctx, signal, &entry)
+	g.record(entry, err)
+	g.logFiring(ctx, entry, err)
+	g.emitFiring(ctx, entry, err)
+
+	return err
+}
+
+// fire moves the token along one transition and fills timings of the entry.
+func (g *Petri[T, V]) fire(ctx context.Context, signal T, entry *HistoryEntry[T, V]) error {
+	var (
+		transition *Transition[T, V]
+		nextPlace  *Place[T, V]
+		err        error
+	)
+
+	entry.Timings.ChooseTo = timed(func() { transition, err = g.chooseTo(ctx, g.Current, signal) })
 	if err != nil {
 		return fmt.Errorf("graph %v choosing transition %v: %w", g.ID, g.Current.ID, err)
 	}
 
+	if transition == nil {
+		return fmt.Errorf("graph %v no transition chosen at place %v: %w", g.ID, g.Current.ID, ErrForbiddenTransition)
+	}
+
+	entry.Transition = transition.ID
+
 	_, ok := g.Current.to[transition.ID]
 	if !ok {
-		return fmt.Errorf("graph %v forbitten transition %v for place %v", g.ID, transition.ID, g.Current.ID)
+		return fmt.Errorf("graph %v transition %v for place %v: %w", g.ID, transition.ID, g.Current.ID, ErrForbiddenTransition)
 	}
 
-	nextPlace, err := transition.Handler.Handle(g.Current, signal)
+	entry.Timings.Handle = timed(func() { nextPlace, err = g.handle(ctx, transition, g.Current, signal) })
 	if err != nil {
 		return fmt.Errorf("graph %v handling signal %v by transition %v: %w", g.ID, signal, transition.ID, err)
 	}
 
+	if nextPlace == nil {
+		err = fmt.Errorf("graph %v no place calculated from transition %v: %w", g.ID, transition.ID, ErrForbiddenPlace)
+
+		return g.rollback(err, g.compensateTransition(ctx, transition, g.Current, signal))
+	}
+
+	entry.To = nextPlace.ID
+
+	from := g.Current
 	_, ok = transition.to[nextPlace.ID]
 	if !ok {
-		return fmt.Errorf("graph %v forbitten place %v calculated from transition %v", g.ID, nextPlace.ID, transition.ID)
+		err = fmt.Errorf("graph %v place %v calculated from transition %v: %w", g.ID, nextPlace.ID, transition.ID, ErrForbiddenPlace)
+
+		return g.rollback(err, g.compensateTransition(ctx, transition, from, signal))
 	}
 
-	err = g.Current.Handler.HandleOut(nextPlace)
+	entry.Timings.HandleOut = timed(func() { err = g.placeHandleOut(ctx, from, nextPlace, signal) })
 	if err != nil {
-		return fmt.Errorf("graph %v exiting place %v to %v: %w", g.ID, g.Current.ID, nextPlace.ID, err)
+		err = fmt.Errorf("graph %v exiting place %v to %v: %w", g.ID, from.ID, nextPlace.ID, err)
+
+		return g.rollback(err, g.compensateTransition(ctx, transition, from, signal))
 	}
 
-	oldCurrent := g.Current
 	g.Current = nextPlace
 
-	err = g.Current.Handler.HandleIn(oldCurrent)
+	entry.Timings.HandleIn = timed(func() { err = g.placeHandleIn(ctx, g.Current, from, signal) })
 	if err != nil {
-		return fmt.Errorf("graph %v enterfing %v from %v: %w", g.ID, g.Current.ID, oldCurrent.ID, err)
+		err = fmt.Errorf("graph %v enterfing %v from %v: %w", g.ID, g.Current.ID, from.ID, err)
+		g.Current = from
+
+		return g.rollback(err, g.compensateOut(ctx, from, nextPlace, signal), g.compensateTransition(ctx, transition, from, signal))
 	}
 
+	g.Fired = append(g.Fired, Firing[T, V]{Transition: transition.ID, From: from.ID, To: nextPlace.ID, Signal: signal})
+
 	return nil
 }
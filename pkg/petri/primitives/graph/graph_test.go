@@ -154,3 +154,79 @@ func TestPetri_Act(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "next", petri.Current.ID)
 }
+
+type mockCancelHandler struct {
+	mockHandler
+	cancelled bool
+}
+
+func (m *mockCancelHandler) HandleCancel() error {
+	m.cancelled = true
+
+	return nil
+}
+
+func TestPetri_CancelGraph(t *testing.T) {
+	handler := &mockCancelHandler{}
+	startPlace := &graph.Place[int, string]{ID: "start", Handler: &mockPlaceHandle{}}
+
+	petri := &graph.Petri[int, string]{
+		ID:      "testGraph",
+		Start:   startPlace,
+		Handler: handler,
+	}
+
+	err := petri.CancelGraph()
+	assert.NoError(t, err)
+	assert.False(t, handler.cancelled)
+
+	petri.Current = startPlace
+
+	err = petri.CancelGraph()
+	assert.NoError(t, err)
+	assert.True(t, handler.cancelled)
+}
+
+type mockSuspendHandler struct {
+	mockHandler
+	suspended int
+	resumed   int
+}
+
+func (m *mockSuspendHandler) Suspend() error {
+	m.suspended++
+
+	return nil
+}
+
+func (m *mockSuspendHandler) Resume() error {
+	m.resumed++
+
+	return nil
+}
+
+func TestPetri_SuspendResume(t *testing.T) {
+	handler := &mockSuspendHandler{}
+	startPlace := &graph.Place[int, string]{ID: "start", Handler: &mockPlaceHandle{}}
+
+	petri := &graph.Petri[int, string]{
+		ID:      "testGraph",
+		Start:   startPlace,
+		Handler: handler,
+	}
+
+	assert.NoError(t, petri.Suspend())
+	assert.False(t, petri.Suspended)
+
+	petri.Current = startPlace
+
+	assert.NoError(t, petri.Suspend())
+	assert.NoError(t, petri.Suspend())
+	assert.True(t, petri.Suspended)
+	assert.Equal(t, 1, handler.suspended)
+
+	assert.NoError(t, petri.Resume())
+	assert.NoError(t, petri.Resume())
+	assert.False(t, petri.Suspended)
+	assert.Equal(t, 1, handler.resumed)
+}
@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"time"
+)
+
+// DefaultHistoryRetention is used by graphs without SetHistoryRetention.
+var DefaultHistoryRetention = HistoryRetention{MaxEntries: 100}
+
+// HistoryRetention caps the history of a graph. Zero fields mean no limit by
+// that criterion, negative MaxEntries disables the history.
+type HistoryRetention struct {
+	MaxEntries int           `json:"max_entries,omitempty"`
+	MaxAge     time.Duration `json:"max_age,omitempty"`
+}
+
+// HistoryEntry is one firing attempt. Transition and To stay zero when the
+// attempt failed before they were known.
+type HistoryEntry[T any, V comparable] struct {
+	At         time.Time `json:"at"`
+	Signal     T         `json:"signal"`
+	Transition V         `json:"transition"`
+	From       V         `json:"from"`
+	To         V         `json:"to"`
+	Timings    Timings   `json:"timings"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Timings are durations of handler calls made during a firing.
+type Timings struct {
+	ChooseTo  time.Duration `json:"choose_to,omitempty"`
+	Handle    time.Duration `json:"handle,omitempty"`
+	HandleOut time.Duration `json:"handle_out,omitempty"`
+	HandleIn  time.Duration `json:"handle_in,omitempty"`
+}
+
+func (t Timings) Total() time.Duration {
+	return t.ChooseTo + t.Handle + t.HandleOut + t.HandleIn
+}
+
+func (g *Petri[T, V]) SetHistoryRetention(r HistoryRetention) *Petri[T, V] {
+	g.Retention = r
+	g.History = applyRetention(r, g.History, time.Now())
+
+	return g
+}
+
+func (g *Petri[T, V]) record(entry HistoryEntry[T, V], err error) {
+	retention := g.Retention
+	if retention == (HistoryRetention{}) {
+		retention = DefaultHistoryRetention
+	}
+
+	if retention.MaxEntries < 0 {
+		return
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	g.History = applyRetention(retention, append(g.History, entry), entry.At)
+}
+
+func applyRetention[T any, V comparable](r HistoryRetention, history []HistoryEntry[T, V], now time.Time) []HistoryEntry[T, V] {
+	if r.MaxAge > 0 {
+		cut := 0
+		for cut < len(history) && now.Sub(history[cut].At) > r.MaxAge {
+			cut++
+		}
+
+		history = history[cut:]
+	}
+
+	if r.MaxEntries > 0 && len(history) > r.MaxEntries {
+		history = history[len(history)-r.MaxEntries:]
+	}
+
+	if len(history) == 0 {
+		return nil
+	}
+
+	return history
+}
+
+func timed(fn func()) time.Duration {
+	started := time.Now()
+	fn()
+
+	return time.Since(started)
+}
@@ -0,0 +1,85 @@
+package graph_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+func TestPetri_History(t *testing.T) {
+	var log []string
+	petri := makeCompensatingGraph(&log, nil, nil, nil)
+
+	assert.NoError(t, petri.Act(7))
+	assert.Len(t, petri.History, 1)
+
+	entry := petri.History[0]
+	assert.Equal(t, 7, entry.Signal)
+	assert.Equal(t, "move", entry.Transition)
+	assert.Equal(t, "start", entry.From)
+	assert.Equal(t, "next", entry.To)
+	assert.Empty(t, entry.Error)
+	assert.False(t, entry.At.IsZero())
+
+	data, err := json.Marshal(petri)
+	assert.NoError(t, err)
+
+	var restored graph.Petri[int, string]
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, "move", restored.History[0].Transition)
+}
+
+func TestPetri_History_Error(t *testing.T) {
+	var log []string
+	petri := makeCompensatingGraph(&log, errors.New("in failed"), nil, nil)
+
+	assert.Error(t, petri.Act(1))
+	assert.Len(t, petri.History, 1)
+	assert.Equal(t, "next", petri.History[0].To)
+	assert.Contains(t, petri.History[0].Error, "in failed")
+}
+
+func TestPetri_History_Retention(t *testing.T) {
+	var log []string
+	petri := makeCompensatingGraph(&log, errors.New("in failed"), nil, nil).
+		SetHistoryRetention(graph.HistoryRetention{MaxEntries: 2})
+
+	for i := 0; i < 3; i++ {
+		assert.Error(t, petri.Act(i))
+	}
+
+	assert.Len(t, petri.History, 2)
+	assert.Equal(t, 1, petri.History[0].Signal)
+
+	petri.History[0].At = time.Now().Add(-time.Hour)
+	petri.SetHistoryRetention(graph.HistoryRetention{MaxAge: time.Minute})
+	assert.Len(t, petri.History, 1)
+	assert.Equal(t, 2, petri.History[0].Signal)
+
+	petri.SetHistoryRetention(graph.HistoryRetention{MaxEntries: -1})
+	assert.Error(t, petri.Act(3))
+	assert.Len(t, petri.History, 1)
+}
+
+func TestPetri_History_RetentionPersisted(t *testing.T) {
+	var log []string
+	petri := makeCompensatingGraph(&log, errors.New("in failed"), nil, nil).
+		SetHistoryRetention(graph.HistoryRetention{MaxEntries: 1, MaxAge: time.Hour})
+
+	data, err := json.Marshal(petri)
+	assert.NoError(t, err)
+
+	restored := makeCompensatingGraph(&log, errors.New("in failed"), nil, nil)
+	assert.NoError(t, json.Unmarshal(data, restored))
+	assert.Equal(t, graph.HistoryRetention{MaxEntries: 1, MaxAge: time.Hour}, restored.Retention)
+
+	for i := 0; i < 3; i++ {
+		assert.Error(t, restored.Act(i))
+	}
+
+	assert.Len(t, restored.History, 1)
+}
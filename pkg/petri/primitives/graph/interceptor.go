@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// Stage names a handler call made by the graph.
+type Stage int
+
+const (
+	StageChooseTo Stage = iota
+	StageHandle
+	StagePlaceHandleIn
+	StagePlaceHandleOut
+	StageGraphHandleIn
+	StageGraphHandleOut
+	StageGraphCancel
+	StageGraphSuspend
+	StageGraphResume
+	StageCompensate
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageChooseTo:
+		return "choose_to"
+	case StageHandle:
+		return "handle"
+	case StagePlaceHandleIn:
+		return "place_handle_in"
+	case StagePlaceHandleOut:
+		return "place_handle_out"
+	case StageGraphHandleIn:
+		return "graph_handle_in"
+	case StageGraphHandleOut:
+		return "graph_handle_out"
+	case StageGraphCancel:
+		return "graph_cancel"
+	case StageGraphSuspend:
+		return "graph_suspend"
+	case StageGraphResume:
+		return "graph_resume"
+	case StageCompensate:
+		return "compensate"
+	default:
+		return fmt.Sprintf("Stage(%d)", int(s))
+	}
+}
+
+// Call describes an intercepted handler call. NodeID is the place or the
+// transition of the call, for graph stages it is the graph ID. Signal is zero
+// for calls made while starting or finishing the graph.
+type Call[T any, V comparable] struct {
+	Stage   Stage
+	GraphID V
+	NodeID  V
+	Signal  T
+}
+
+// Interceptor wraps a handler call. It must call next to run the handler and
+// may change the context or the returned error.
+type Interceptor[T any, V comparable] func(ctx context.Context, call Call[T, V], next func(context.Context) error) error
+
+type interceptorsKey[T any, V comparable] struct{}
+
+// WithInterceptors returns a context carrying interceptors for every graph
+// acting with it. They run outside of the graph's own interceptors.
+func WithInterceptors[T any, V comparable](ctx context.Context, interceptors ...Interceptor[T, V]) context.Context {
+	inherited, _ := ctx.Value(interceptorsKey[T, V]{}).([]Interceptor[T, V])
+
+	chain := make([]Interceptor[T, V], 0, len(inherited)+len(interceptors))
+	chain = append(chain, inherited...)
+	chain = append(chain, interceptors...)
+
+	return context.WithValue(ctx, interceptorsKey[T, V]{}, chain)
+}
+
+// Use adds interceptors around every handler call of the graph. The first
+// added interceptor is the outermost one.
+func (g *Petri[T, V]) Use(interceptors ...Interceptor[T, V]) *Petri[T, V] {
+	g.interceptors = append(g.interceptors, interceptors...)
+
+	return g
+}
+
+// invoke runs the handler call through interceptors. A panic in the handler
+// is returned as *PanicError, so interceptors see it as an ordinary error.
+func (g *Petri[T, V]) invoke(ctx context.Context, call Call[T, V], handler func(context.Context) error) error {
+	fn := func(ctx context.Context) error {
+		return recovered(ctx, call, handler)
+	}
+
+	inherited, _ := ctx.Value(interceptorsKey[T, V]{}).([]Interceptor[T, V])
+	if len(inherited) == 0 && len(g.interceptors) == 0 {
+		return fn(ctx)
+	}
+
+	chain := make([]Interceptor[T, V], 0, len(inherited)+len(g.interceptors))
+	chain = append(chain, inherited...)
+	chain = append(chain, g.interceptors...)
+
+	next := fn
+	for i := len(chain) - 1; i >= 0; i-- {
+		interceptor, inner := chain[i], next
+		next = func(ctx context.Context) error {
+			return interceptor(ctx, call, inner)
+		}
+	}
+
+	return next(ctx)
+}
+
+func (g *Petri[T, V]) call(stage Stage) Call[T, V] {
+	return Call[T, V]{Stage: stage, GraphID: g.ID, NodeID: g.ID}
+}
+
+func (g *Petri[T, V]) graphHandleIn(ctx context.Context) error {
+	return g.invoke(ctx, g.call(StageGraphHandleIn), func(ctx context.Context) error {
+		return graphHandleIn(ctx, g.Handler)
+	})
+}
+
+func (g *Petri[T, V]) graphHandleOut(ctx context.Context) error {
+	return g.invoke(ctx, g.call(StageGraphHandleOut), func(ctx context.Context) error {
+		return graphHandleOut(ctx, g.Handler)
+	})
+}
+
+func (g *Petri[T, V]) placeHandleIn(ctx context.Context, p *Place[T, V], from *Place[T, V], signal T) error {
+	call := Call[T, V]{Stage: StagePlaceHandleIn, GraphID: g.ID, NodeID: p.ID, Signal: signal}
+
+	return g.invoke(ctx, call, func(ctx context.Context) error {
+		return placeHandleIn(ctx, p, from)
+	})
+}
+
+func (g *Petri[T, V]) placeHandleOut(ctx context.Context, p *Place[T, V], to *Place[T, V], signal T) error {
+	call := Call[T, V]{Stage: StagePlaceHandleOut, GraphID: g.ID, NodeID: p.ID, Signal: signal}
+
+	return g.invoke(ctx, call, func(ctx context.Context) error {
+		return placeHandleOut(ctx, p, to)
+	})
+}
+
+func (g *Petri[T, V]) chooseTo(ctx context.Context, p *Place[T, V], signal T) (*Transition[T, V], error) {
+	var transition *Transition[T, V]
+
+	call := Call[T, V]{Stage: StageChooseTo, GraphID: g.ID, NodeID: p.ID, Signal: signal}
+	err := g.invoke(ctx, call, func(ctx context.Context) error {
+		var err error
+		transition, err = placeChooseTo(ctx, p, signal)
+
+		return err
+	})
+
+	return transition, err
+}
+
+func (g *Petri[T, V]) handle(ctx context.Context, t *Transition[T, V], from *Place[T, V], signal T) (*Place[T, V], error) {
+	var next *Place[T, V]
+
+	call := Call[T, V]{Stage: StageHandle, GraphID: g.ID, NodeID: t.ID, Signal: signal}
+	err := g.invoke(ctx, call, func(ctx context.Context) error {
+		var err error
+		next, err = transitionHandle(ctx, t, from, signal)
+
+		return err
+	})
+
+	return next, err
+}
+
+func zero[T any]() T {
+	var result T
+
+	return result
+}
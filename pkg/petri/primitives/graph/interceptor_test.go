@@ -0,0 +1,61 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+func recordingInterceptor(name string, log *[]string) graph.Interceptor[int, string] {
+	return func(ctx context.Context, call graph.Call[int, string], next func(context.Context) error) error {
+		*log = append(*log, name+" "+call.Stage.String()+" "+call.NodeID)
+
+		return next(ctx)
+	}
+}
+
+func TestPetri_Use(t *testing.T) {
+	var log, compensations []string
+	petri := makeCompensatingGraph(&compensations, nil, nil, nil).
+		Use(recordingInterceptor("graph", &log))
+
+	ctx := graph.WithInterceptors(context.Background(), recordingInterceptor("ctx", &log))
+
+	assert.NoError(t, petri.ActContext(ctx, 1))
+	assert.NoError(t, petri.FinishGraphContext(ctx))
+	assert.Equal(t, []string{
+		"ctx choose_to start",
+		"graph choose_to start",
+		"ctx handle move",
+		"graph handle move",
+		"ctx place_handle_out start",
+		"graph place_handle_out start",
+		"ctx place_handle_in next",
+		"graph place_handle_in next",
+		"ctx place_handle_out next",
+		"graph place_handle_out next",
+		"ctx graph_handle_out testGraph",
+		"graph graph_handle_out testGraph",
+	}, log)
+}
+
+func TestPetri_Use_OverridesError(t *testing.T) {
+	var compensations []string
+	failure := errors.New("denied")
+
+	petri := makeCompensatingGraph(&compensations, nil, nil, nil).
+		Use(func(ctx context.Context, call graph.Call[int, string], next func(context.Context) error) error {
+			if call.Stage == graph.StageHandle {
+				return failure
+			}
+
+			return next(ctx)
+		})
+
+	err := petri.Act(1)
+	assert.True(t, errors.Is(err, failure))
+	assert.Equal(t, "start", petri.Current.ID)
+}
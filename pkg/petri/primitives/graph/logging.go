@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying a logger for every graph acting with
+// it that has no logger of its own.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// SetLogger enables structured logging of the graph lifecycle and firings.
+// Logging is off while the logger is nil.
+func (g *Petri[T, V]) SetLogger(logger *slog.Logger) *Petri[T, V] {
+	g.logger = logger
+
+	return g
+}
+
+// logging returns the logger to use for the record or nil when the record is
+// dropped, so callers build attributes only for enabled records.
+func (g *Petri[T, V]) logging(ctx context.Context, level slog.Level) *slog.Logger {
+	logger := g.logger
+	if logger == nil {
+		logger, _ = ctx.Value(loggerKey{}).(*slog.Logger)
+	}
+
+	if logger == nil || !logger.Enabled(ctx, level) {
+		return nil
+	}
+
+	return logger
+}
+
+func (g *Petri[T, V]) logFiring(ctx context.Context, entry HistoryEntry[T, V], err error) {
+	level := slog.LevelDebug
+	msg := "transition fired"
+
+	switch {
+	case errors.Is(err, ErrSignalIgnored):
+		msg = "signal ignored"
+	case err != nil:
+		level = slog.LevelError
+		msg = "firing failed"
+	}
+
+	logger := g.logging(ctx, level)
+	if logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.Any("graph", g.ID),
+		slog.Any("signal", entry.Signal),
+		slog.Any("from", entry.From),
+		slog.Duration("duration", entry.Timings.Total()),
+	}
+
+	var none V
+	if entry.Transition != none {
+		attrs = append(attrs, slog.Any("transition", entry.Transition))
+	}
+
+	if entry.To != none {
+		attrs = append(attrs, slog.Any("to", entry.To))
+	}
+
+	if err != nil && level == slog.LevelError {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func (g *Petri[T, V]) logLifecycle(ctx context.Context, msg string, err error) {
+	level := slog.LevelInfo
+	if err != nil {
+		level = slog.LevelError
+		msg += " failed"
+	}
+
+	logger := g.logging(ctx, level)
+	if logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{slog.Any("graph", g.ID)}
+	if g.Current != nil {
+		attrs = append(attrs, slog.Any("place", g.Current.ID))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
@@ -0,0 +1,63 @@
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+func newTestLogger(out *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == "duration") {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+}
+
+func makeLoggedGraph() *graph.Petri[int, string] {
+	placeHandler := &mockPlaceHandle{}
+	start := graph.NewPlace[int, string]("start", placeHandler)
+	finish := graph.NewPlace[int, string]("finish", &mockPlaceHandle{})
+	transition := graph.NewTransition[int, string]("go", &mocktransitionHandler{result: finish}).AddTo(finish)
+	placeHandler.choose = transition
+	start.AddTransition(transition)
+
+	return graph.NewPetri[int, string]("testGraph", &mockHandler{}).
+		SetStartPlace(start).
+		SetFinishPlace(finish)
+}
+
+func TestPetri_SetLogger(t *testing.T) {
+	out := bytes.Buffer{}
+	expected := `level=INFO msg="graph started" graph=testGraph place=start
+level=DEBUG msg="transition fired" graph=testGraph signal=7 from=start transition=go to=finish
+level=INFO msg="graph finished" graph=testGraph place=finish
+`
+
+	petri := makeLoggedGraph().SetLogger(newTestLogger(&out))
+
+	assert.NoError(t, petri.Act(7))
+	assert.NoError(t, petri.FinishGraph())
+	assert.Equal(t, expected, out.String())
+}
+
+func TestPetri_WithLogger(t *testing.T) {
+	out := bytes.Buffer{}
+
+	petri := makeLoggedGraph()
+	petri.Finish.Handler = &mockPlaceHandle{inErr: errors.New("closed")}
+
+	ctx := graph.WithLogger(context.Background(), newTestLogger(&out))
+	assert.Error(t, petri.ActContext(ctx, 7))
+	assert.Contains(t, out.String(), `level=ERROR msg="firing failed" graph=testGraph signal=7 from=start transition=go`)
+}
@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is returned instead of a panic raised by a handler.
+type PanicError[V comparable] struct {
+	Stage   Stage
+	GraphID V
+	NodeID  V
+	Signal  any
+	Value   any
+	Stack   []byte
+}
+
+func (e *PanicError[V]) Error() string {
+	return fmt.Sprintf(
+		"%v: %v of graph %v, node %v, signal %v: %v",
+		ErrPanic, e.Stage, e.GraphID, e.NodeID, e.Signal, e.Value,
+	)
+}
+
+func (e *PanicError[V]) Is(target error) bool {
+	return target == ErrPanic
+}
+
+// Unwrap gives access to the panic value when it is an error.
+func (e *PanicError[V]) Unwrap() error {
+	err, _ := e.Value.(error)
+
+	return err
+}
+
+// recovered runs the handler and returns its failure or panic as *HandlerError.
+func recovered[T any, V comparable](ctx context.Context, call Call[T, V], fn func(context.Context) error) (err error) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+
+		err = &HandlerError[V]{
+			Stage:   call.Stage,
+			GraphID: call.GraphID,
+			NodeID:  call.NodeID,
+			Err: &PanicError[V]{
+				Stage:   call.Stage,
+				GraphID: call.GraphID,
+				NodeID:  call.NodeID,
+				Signal:  call.Signal,
+				Value:   value,
+				Stack:   debug.Stack(),
+			},
+		}
+	}()
+
+	err = fn(ctx)
+	if err != nil {
+		return &HandlerError[V]{Stage: call.Stage, GraphID: call.GraphID, NodeID: call.NodeID, Err: err}
+	}
+
+	return nil
+}
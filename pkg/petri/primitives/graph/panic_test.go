@@ -0,0 +1,56 @@
+package graph_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+type panickingTransitionHandler struct {
+	value any
+}
+
+func (h *panickingTransitionHandler) Handle(*graph.Place[int, string], int) (*graph.Place[int, string], error) {
+	panic(h.value)
+}
+
+func TestPetri_Act_RecoversPanic(t *testing.T) {
+	cause := errors.New("nil map")
+
+	placeHandler := &mockPlaceHandle{}
+	start := graph.NewPlace[int, string]("start", placeHandler)
+	next := graph.NewPlace[int, string]("next", &mockPlaceHandle{})
+	transition := graph.NewTransition[int, string]("boom", &panickingTransitionHandler{value: cause}).AddTo(next)
+	placeHandler.choose = transition
+	start.AddTransition(transition)
+
+	petri := graph.NewPetri[int, string]("testGraph", &mockHandler{}).
+		SetStartPlace(start).
+		SetCurrentPlace(start).
+		SetFinishPlace(next)
+
+	err := petri.Act(42)
+	assert.True(t, errors.Is(err, graph.ErrPanic))
+	assert.True(t, errors.Is(err, cause))
+
+	var panicErr *graph.PanicError[string]
+	assert.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, graph.StageHandle, panicErr.Stage)
+	assert.Equal(t, "testGraph", panicErr.GraphID)
+	assert.Equal(t, "boom", panicErr.NodeID)
+	assert.Equal(t, 42, panicErr.Signal)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.Equal(t, "start", petri.Current.ID)
+}
+
+func TestPetri_Act_NoTransitionChosen(t *testing.T) {
+	start := graph.NewPlace[int, string]("start", &mockPlaceHandle{})
+
+	petri := graph.NewPetri[int, string]("testGraph", &mockHandler{}).
+		SetStartPlace(start).
+		SetCurrentPlace(start)
+
+	assert.ErrorContains(t, petri.Act(1), "no transition chosen")
+}
@@ -9,9 +9,12 @@ type PlaceHandler[T any, V comparable] interface {
 }
 
 type Place[T any, V comparable] struct {
-	ID      V `json:"id,omitempty"`
-	Handler PlaceHandler[T, V]
+	ID V `json:"id,omitempty"`
+	// Safe marks a place where the graph may be preempted by a higher priority graph
+	Safe    bool               `json:"safe,omitempty"`
+	Handler PlaceHandler[T, V] `json:"-"`
 	to      map[V]struct{}
+	arcs    []*Transition[T, V]
 }
 
 func NewPlace[T any, V comparable](id V, handler PlaceHandler[T, V]) *Place[T, V] {
@@ -27,11 +30,27 @@ func (p *Place[T, V]) AddTransition(s *Transition[T, V]) *Place[T, V] {
 		p.to = make(map[V]struct{})
 	}
 
+	_, ok := p.to[s.ID]
+	if !ok {
+		p.arcs = append(p.arcs, s)
+	}
+
 	p.to[s.ID] = struct{}{}
 
 	return p
 }
 
+// Transitions returns outgoing transitions in the order they were added.
+func (p *Place[T, V]) Transitions() []*Transition[T, V] {
+	return p.arcs
+}
+
+func (p *Place[T, V]) SetSafe(safe bool) *Place[T, V] {
+	p.Safe = safe
+
+	return p
+}
+
 func (p *Place[T, V]) GetTo() map[V]struct{} {
 	return p.to
 }
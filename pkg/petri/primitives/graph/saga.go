@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+)
+
+// Firing is a committed firing of a transition.
+type Firing[T any, V comparable] struct {
+	Transition V `json:"transition"`
+	From       V `json:"from"`
+	To         V `json:"to"`
+	Signal     T `json:"signal"`
+}
+
+// Abort walks back through fired transitions and invokes their compensations
+// in reverse order, then cancels the graph. Transitions without
+// TransitionCompensator are skipped. When a compensation fails, the firings
+// that are not compensated yet stay in Fired, so Abort can be called again.
+//
+// Fired nodes are looked up by ID along the arcs of the graph. JSON keeps the
+// IDs of the start, finish and current places only, so a graph loaded from a
+// storage gets its structure and handlers back, the same as it does before it
+// acts, or Abort fails with ErrUnknownNode and keeps Fired as it is.
+func (g *Petri[T, V]) Abort() error {
+	return g.AbortContext(context.Background())
+}
+
+// AbortContext aborts the graph like Abort. Compensation stops once the
+// context is done, the firings left stay in Fired.
+func (g *Petri[T, V]) AbortContext(ctx context.Context) error {
+	for i := len(g.Fired) - 1; i >= 0; i-- {
+		err := ctx.Err()
+		if err != nil {
+			return fmt.Errorf("aborting graph %v: %w", g.ID, err)
+		}
+
+		fired := g.Fired[i]
+
+		transition, ok := g.Transition(fired.Transition)
+		if !ok {
+			return fmt.Errorf("aborting graph %v: transition %v: %w", g.ID, fired.Transition, ErrUnknownNode)
+		}
+
+		from, ok := g.Place(fired.From)
+		if !ok {
+			return fmt.Errorf("aborting graph %v: place %v: %w", g.ID, fired.From, ErrUnknownNode)
+		}
+
+		err = g.compensateTransition(ctx, transition, from, fired.Signal)()
+		if err != nil {
+			return fmt.Errorf("aborting graph %v: %w", g.ID, err)
+		}
+
+		g.Fired = g.Fired[:i]
+	}
+
+	err := g.CancelGraphContext(ctx)
+	if err != nil {
+		return fmt.Errorf("aborting graph %v: %w", g.ID, err)
+	}
+
+	g.Current = nil
+
+	return nil
+}
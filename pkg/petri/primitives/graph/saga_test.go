@@ -0,0 +1,151 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+type sagaPlaceHandle struct {
+	mockPlaceHandle
+}
+
+func (m *sagaPlaceHandle) ChooseTo(int) (*graph.Transition[int, string], error) {
+	return m.choose, nil
+}
+
+func makeSagaGraph(log *[]string, failOn string) *graph.Petri[int, string] {
+	finish := graph.NewPlace[int, string]("finish", &mockPlaceHandle{})
+	middle := graph.NewPlace[int, string]("middle", &sagaPlaceHandle{})
+	start := graph.NewPlace[int, string]("start", &sagaPlaceHandle{})
+
+	reserve := graph.NewTransition[int, string]("reserve", &compensatingTransitionHandler{
+		mocktransitionHandler: mocktransitionHandler{result: middle},
+		log:                   log,
+	}).AddTo(middle)
+
+	var chargeErr error
+	if failOn == "charge" {
+		chargeErr = errors.New("refund failed")
+	}
+
+	charge := graph.NewTransition[int, string]("charge", &compensatingTransitionHandler{
+		mocktransitionHandler: mocktransitionHandler{result: finish},
+		log:                   log,
+		err:                   chargeErr,
+	}).AddTo(finish)
+
+	start.Handler.(*sagaPlaceHandle).choose = reserve
+	start.AddTransition(reserve)
+	middle.Handler.(*sagaPlaceHandle).choose = charge
+	middle.AddTransition(charge)
+
+	return graph.NewPetri[int, string]("order", &mockHandler{}).
+		SetStartPlace(start).
+		SetFinishPlace(finish)
+}
+
+func TestPetri_Abort(t *testing.T) {
+	var log []string
+	petri := makeSagaGraph(&log, "")
+
+	assert.NoError(t, petri.Act(1))
+	assert.NoError(t, petri.Act(2))
+	assert.Equal(t, []graph.Firing[int, string]{
+		{Transition: "reserve", From: "start", To: "middle", Signal: 1},
+		{Transition: "charge", From: "middle", To: "finish", Signal: 2},
+	}, petri.Fired)
+
+	assert.NoError(t, petri.Abort())
+	assert.Equal(t, []string{"compensate transition from middle", "compensate transition from start"}, log)
+	assert.Empty(t, petri.Fired)
+	assert.Nil(t, petri.Current)
+}
+
+func TestPetri_Abort_CompensationFails(t *testing.T) {
+	var log []string
+	petri := makeSagaGraph(&log, "charge")
+
+	assert.NoError(t, petri.Act(1))
+	assert.NoError(t, petri.Act(2))
+
+	err := petri.Abort()
+	assert.ErrorContains(t, err, "refund failed")
+	assert.Len(t, petri.Fired, 2)
+	assert.Equal(t, "finish", petri.Current.ID)
+}
+
+func TestPetri_AbortContext_Cancelled(t *testing.T) {
+	var log []string
+	petri := makeSagaGraph(&log, "")
+
+	assert.NoError(t, petri.Act(1))
+	assert.NoError(t, petri.Act(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := petri.AbortContext(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, log)
+	assert.Len(t, petri.Fired, 2)
+	assert.Equal(t, "finish", petri.Current.ID)
+}
+
+func TestPetri_Abort_Loaded(t *testing.T) {
+	var log []string
+	petri := makeSagaGraph(&log, "")
+
+	assert.NoError(t, petri.Act(1))
+	assert.NoError(t, petri.Act(2))
+
+	data, err := json.Marshal(petri)
+	assert.NoError(t, err)
+
+	loaded := &graph.Petri[int, string]{}
+	assert.NoError(t, json.Unmarshal(data, loaded))
+
+	err = loaded.Abort()
+	assert.ErrorIs(t, err, graph.ErrUnknownNode)
+	assert.Len(t, loaded.Fired, 2)
+	assert.Empty(t, log)
+
+	structure := makeSagaGraph(&log, "")
+	loaded.Handler = structure.Handler
+	loaded.Start = structure.Start
+	loaded.Finish = structure.Finish
+	loaded.Current, _ = structure.Place(loaded.Current.ID)
+
+	assert.NoError(t, loaded.Abort())
+	assert.Equal(t, []string{"compensate transition from middle", "compensate transition from start"}, log)
+	assert.Empty(t, loaded.Fired)
+	assert.Nil(t, loaded.Current)
+}
+
+func TestPetri_PlacesAndTransitions(t *testing.T) {
+	var log []string
+	petri := makeSagaGraph(&log, "")
+
+	var places []string
+	for _, p := range petri.Places() {
+		places = append(places, p.ID)
+	}
+
+	var transitions []string
+	for _, tr := range petri.Transitions() {
+		transitions = append(transitions, tr.ID)
+	}
+
+	assert.Equal(t, []string{"start", "middle", "finish"}, places)
+	assert.Equal(t, []string{"reserve", "charge"}, transitions)
+
+	_, ok := petri.Transition("charge")
+	assert.True(t, ok)
+
+	_, ok = petri.Place("unknown")
+	assert.False(t, ok)
+}
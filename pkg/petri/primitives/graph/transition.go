@@ -5,9 +5,10 @@ type TransitionHandler[T any, V comparable] interface {
 }
 
 type Transition[T any, V comparable] struct {
-	ID      V `json:"id,omitempty"`
-	Handler TransitionHandler[T, V]
+	ID      V                       `json:"id,omitempty"`
+	Handler TransitionHandler[T, V] `json:"-"`
 	to      map[V]struct{}
+	arcs    []*Place[T, V]
 }
 
 func NewTransition[T any, V comparable](id V, handler TransitionHandler[T, V]) *Transition[T, V] {
@@ -23,11 +24,21 @@ func (t *Transition[T, V]) AddTo(n *Place[T, V]) *Transition[T, V] {
 		t.to = make(map[V]struct{})
 	}
 
+	_, ok := t.to[n.ID]
+	if !ok {
+		t.arcs = append(t.arcs, n)
+	}
+
 	t.to[n.ID] = struct{}{}
 
 	return t
 }
 
+// Places returns output places in the order they were added.
+func (t *Transition[T, V]) Places() []*Place[T, V] {
+	return t.arcs
+}
+
 func (p *Transition[T, V]) GetTo() map[V]struct{} {
 	return p.to
 }
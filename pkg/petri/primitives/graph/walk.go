@@ -0,0 +1,80 @@
+package graph
+
+// Places returns places reachable from the start place in breadth-first order.
+// Places that are only known as finish or current place are appended after them.
+func (g *Petri[T, V]) Places() []*Place[T, V] {
+	places, _ := g.walk()
+
+	return places
+}
+
+// Transitions returns transitions reachable from the start place in breadth-first order.
+func (g *Petri[T, V]) Transitions() []*Transition[T, V] {
+	_, transitions := g.walk()
+
+	return transitions
+}
+
+func (g *Petri[T, V]) Place(id V) (*Place[T, V], bool) {
+	for _, p := range g.Places() {
+		if p.ID == id {
+			return p, true
+		}
+	}
+
+	return nil, false
+}
+
+func (g *Petri[T, V]) Transition(id V) (*Transition[T, V], bool) {
+	for _, t := range g.Transitions() {
+		if t.ID == id {
+			return t, true
+		}
+	}
+
+	return nil, false
+}
+
+func (g *Petri[T, V]) walk() ([]*Place[T, V], []*Transition[T, V]) {
+	var (
+		places      []*Place[T, V]
+		transitions []*Transition[T, V]
+	)
+
+	seenPlaces := make(map[V]struct{})
+	seenTransitions := make(map[V]struct{})
+
+	visit := func(p *Place[T, V]) {
+		if p == nil {
+			return
+		}
+
+		if _, ok := seenPlaces[p.ID]; ok {
+			return
+		}
+
+		seenPlaces[p.ID] = struct{}{}
+		places = append(places, p)
+	}
+
+	visit(g.Start)
+	for i := 0; i < len(places); i++ {
+		for _, t := range places[i].arcs {
+			if _, ok := seenTransitions[t.ID]; ok {
+				continue
+			}
+
+			seenTransitions[t.ID] = struct{}{}
+			transitions = append(transitions, t)
+
+			for _, next := range t.arcs {
+				visit(next)
+			}
+		}
+	}
+
+	visit(g.Finish)
+	visit(g.Current)
+
+	return places, transitions
+}
@@ -0,0 +1,9 @@
+package priority
+
+import (
+	"errors"
+)
+
+var (
+	ErrDuplicateID = errors.New("graph id is already in the queue")
+)
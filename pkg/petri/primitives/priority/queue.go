@@ -1,7 +1,10 @@
 package priority
 
 import (
+	"encoding/json"
+	"fmt"
 	"math"
+	"sort"
 	"sync"
 
 	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
@@ -10,15 +13,53 @@ import (
 
 type Queue[T any, V comparable] struct {
 	GrQu        map[int]*queue.Queue[graph.Petri[T, V]] `json:"objects"`
+	Dead        []*DeadLetter[T, V]                     `json:"dead,omitempty"`
 	maxPriority int
+	index       map[V]entry[T, V]
 	mu          *sync.Mutex
 }
 
+// DeadLetter граф, снятый с очереди из-за ошибки, с уровнем приоритета для возврата
+type DeadLetter[T any, V comparable] struct {
+	Level int                `json:"level"`
+	Graph *graph.Petri[T, V] `json:"graph"`
+	Error string             `json:"error"`
+}
+
+type entry[T any, V comparable] struct {
+	level int
+	graph *graph.Petri[T, V]
+}
+
 func NewPriorityQueue[T any, V comparable]() *Queue[T, V] {
 	return &Queue[T, V]{
-		GrQu: make(map[int]*queue.Queue[graph.Petri[T, V]]),
-		mu:   &sync.Mutex{},
+		GrQu:  make(map[int]*queue.Queue[graph.Petri[T, V]]),
+		index: make(map[V]entry[T, V]),
+		mu:    &sync.Mutex{},
+	}
+}
+
+// UnmarshalJSON восстанавливает состояние очереди из хранилища вместе со служебными полями
+func (p *Queue[T, V]) UnmarshalJSON(data []byte) error {
+	type stored Queue[T, V]
+
+	var result stored
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return err
 	}
+
+	if result.GrQu == nil {
+		result.GrQu = make(map[int]*queue.Queue[graph.Petri[T, V]])
+	}
+
+	p.GrQu = result.GrQu
+	p.Dead = result.Dead
+	p.maxPriority = calculateMax(p.GrQu)
+	p.index = nil
+	p.mu = &sync.Mutex{}
+
+	return nil
 }
 
 // Peek Читаем актуальный объект, без изменения состояния
@@ -43,11 +84,21 @@ func (p *Queue[T, V]) Peek() (*graph.Petri[T, V], int, bool) {
 	return result, p.maxPriority, true
 }
 
-// Push добавить объект
-func (p *Queue[T, V]) Push(priority int, obj *graph.Petri[T, V]) {
+// Push добавить объект, ID графа должен быть уникален в очереди
+func (p *Queue[T, V]) Push(priority int, obj *graph.Petri[T, V]) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if _, ok := p.indexed()[obj.ID]; ok {
+		return fmt.Errorf("graph %v, level %d: %w", obj.ID, priority, ErrDuplicateID)
+	}
+
+	p.push(priority, obj)
+
+	return nil
+}
+
+func (p *Queue[T, V]) push(priority int, obj *graph.Petri[T, V]) {
 	// Check for integer overflow or invalid input
 	if priority > math.MaxInt {
 		panic("invalid input: index out of range")
@@ -58,12 +109,14 @@ func (p *Queue[T, V]) Push(priority int, obj *graph.Petri[T, V]) {
 		st = queue.NewQueue[graph.Petri[T, V]]()
 		p.GrQu[priority] = st
 
-		if priority > p.maxPriority {
+		// в пустой очереди новый уровень старший, даже если он отрицательный
+		if len(p.GrQu) == 1 || priority > p.maxPriority {
 			p.maxPriority = priority
 		}
 	}
 
 	st.Push(obj)
+	p.indexed()[obj.ID] = entry[T, V]{level: priority, graph: obj}
 }
 
 // PopPriority выдёргивает актуальный элемент с определённого уровня приоритета
@@ -78,21 +131,17 @@ func (p *Queue[T, V]) PopPriority(priority int) (*graph.Petri[T, V], bool) {
 
 	obj, ok := st.Pop()
 	if !ok {
-		delete(p.GrQu, priority)
-		if priority == p.maxPriority {
-			p.maxPriority = calculateMax(p.GrQu)
-		}
+		p.dropLevel(priority)
 
 		return nil, false
 	}
 
 	if len(st.Elements) == 0 {
-		delete(p.GrQu, priority)
-		if priority == p.maxPriority {
-			p.maxPriority = calculateMax(p.GrQu)
-		}
+		p.dropLevel(priority)
 	}
 
+	delete(p.indexed(), obj.ID)
+
 	return obj, true
 }
 
@@ -100,10 +149,201 @@ func (p *Queue[T, V]) Pop() (*graph.Petri[T, V], bool) {
 	return p.PopPriority(p.maxPriority)
 }
 
+// Find ищет граф по ID, возвращает граф и его уровень приоритета
+func (p *Queue[T, V]) Find(id V) (*graph.Petri[T, V], int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.indexed()[id]
+	if !ok {
+		return nil, 0, false
+	}
+
+	return e.graph, e.level, true
+}
+
+// Remove удаляет граф по ID с любого уровня приоритета
+func (p *Queue[T, V]) Remove(id V) (*graph.Petri[T, V], bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.remove(id)
+}
+
+// ChangePriority переносит граф на новый уровень, граф встаёт в конец очереди уровня
+func (p *Queue[T, V]) ChangePriority(id V, newLevel int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.indexed()[id]
+	if !ok {
+		return false
+	}
+
+	if e.level == newLevel {
+		return true
+	}
+
+	obj, ok := p.remove(id)
+	if !ok {
+		return false
+	}
+
+	p.push(newLevel, obj)
+
+	return true
+}
+
+// Bury снимает граф с очереди и переносит его в список упавших
+func (p *Queue[T, V]) Bury(id V, reason error) (*DeadLetter[T, V], bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.indexed()[id]
+	if !ok {
+		return nil, false
+	}
+
+	obj, ok := p.remove(id)
+	if !ok {
+		return nil, false
+	}
+
+	dead := &DeadLetter[T, V]{Level: e.level, Graph: obj}
+	if reason != nil {
+		dead.Error = reason.Error()
+	}
+
+	p.Dead = append(p.Dead, dead)
+
+	return dead, true
+}
+
+// Revive возвращает граф из списка упавших на его прежний уровень приоритета,
+// если в очереди нет другого графа с тем же ID
+func (p *Queue[T, V]) Revive(id V) (*DeadLetter[T, V], bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.indexed()[id]; ok {
+		return nil, false
+	}
+
+	for i, dead := range p.Dead {
+		if dead.Graph.ID != id {
+			continue
+		}
+
+		p.Dead = append(p.Dead[:i:i], p.Dead[i+1:]...)
+		p.push(dead.Level, dead.Graph)
+
+		return dead, true
+	}
+
+	return nil, false
+}
+
+// Range обходит графы от старшего уровня приоритета к младшему, в порядке очереди внутри уровня
+func (p *Queue[T, V]) Range(fn func(level int, obj *graph.Petri[T, V]) bool) {
+	p.mu.Lock()
+	levels := make([]int, 0, len(p.GrQu))
+	for level := range p.GrQu {
+		levels = append(levels, level)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(levels)))
+
+	type item struct {
+		level int
+		obj   *graph.Petri[T, V]
+	}
+
+	items := make([]item, 0, len(p.GrQu))
+	for _, level := range levels {
+		for _, obj := range p.GrQu[level].Elements {
+			items = append(items, item{level: level, obj: obj})
+		}
+	}
+	p.mu.Unlock()
+
+	for _, it := range items {
+		if !fn(it.level, it.obj) {
+			return
+		}
+	}
+}
+
+func (p *Queue[T, V]) remove(id V) (*graph.Petri[T, V], bool) {
+	e, ok := p.indexed()[id]
+	if !ok {
+		return nil, false
+	}
+
+	st, ok := p.GrQu[e.level]
+	if !ok {
+		delete(p.index, id)
+
+		return nil, false
+	}
+
+	obj, ok := st.Remove(func(g *graph.Petri[T, V]) bool {
+		return g == e.graph
+	})
+	if !ok {
+		delete(p.index, id)
+
+		return nil, false
+	}
+
+	if st.IsEmpty() {
+		p.dropLevel(e.level)
+	}
+
+	delete(p.index, id)
+
+	return obj, true
+}
+
+func (p *Queue[T, V]) dropLevel(priority int) {
+	delete(p.GrQu, priority)
+	if priority == p.maxPriority {
+		p.maxPriority = calculateMax(p.GrQu)
+	}
+}
+
+// indexed строит индекс по ID лениво, например после загрузки состояния из хранилища
+func (p *Queue[T, V]) indexed() map[V]entry[T, V] {
+	if p.index != nil {
+		return p.index
+	}
+
+	p.index = make(map[V]entry[T, V])
+	for level, st := range p.GrQu {
+		for _, obj := range st.Elements {
+			p.index[obj.ID] = entry[T, V]{level: level, graph: obj}
+		}
+	}
+
+	return p.index
+}
+
 func (p *Queue[T, V]) GetMaxPriority() int {
 	return p.maxPriority
 }
 
+// Depths возвращает число графов в очереди на каждом уровне приоритета
+func (p *Queue[T, V]) Depths() map[int]int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make(map[int]int, len(p.GrQu))
+	for level, q := range p.GrQu {
+		result[level] = len(q.Elements)
+	}
+
+	return result
+}
+
 func (p *Queue[T, V]) Len() int {
 	return len(p.GrQu)
 }
@@ -1,6 +1,8 @@
 package priority_test
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,10 +13,10 @@ import (
 func TestPriorityQueue_PushPeekPop(t *testing.T) {
 	q := priority.NewPriorityQueue[int, int]()
 
-	obj1 := &graph.Petri[int, int]{}
-	obj2 := &graph.Petri[int, int]{}
-	q.Push(1, obj1)
-	q.Push(2, obj2)
+	obj1 := &graph.Petri[int, int]{ID: 1}
+	obj2 := &graph.Petri[int, int]{ID: 2}
+	assert.NoError(t, q.Push(1, obj1))
+	assert.NoError(t, q.Push(2, obj2))
 
 	peeked, priority, ok := q.Peek()
 	assert.True(t, ok)
@@ -36,10 +38,10 @@ func TestPriorityQueue_PushPeekPop(t *testing.T) {
 func TestPriorityQueue_PopPriority(t *testing.T) {
 	q := priority.NewPriorityQueue[string, int]()
 
-	obj1 := &graph.Petri[string, int]{}
-	obj2 := &graph.Petri[string, int]{}
-	q.Push(3, obj1)
-	q.Push(1, obj2)
+	obj1 := &graph.Petri[string, int]{ID: 1}
+	obj2 := &graph.Petri[string, int]{ID: 2}
+	assert.NoError(t, q.Push(3, obj1))
+	assert.NoError(t, q.Push(1, obj2))
 
 	popped, ok := q.PopPriority(1)
 	assert.True(t, ok)
@@ -62,3 +64,152 @@ func TestPriorityQueue_EmptyPeekPop(t *testing.T) {
 	_, ok = q.Pop()
 	assert.False(t, ok)
 }
+
+func TestPriorityQueue_FindChangeRemove(t *testing.T) {
+	q := priority.NewPriorityQueue[int, string]()
+
+	obj1 := &graph.Petri[int, string]{ID: "a"}
+	obj2 := &graph.Petri[int, string]{ID: "b"}
+	obj3 := &graph.Petri[int, string]{ID: "c"}
+	assert.NoError(t, q.Push(1, obj1))
+	assert.NoError(t, q.Push(1, obj2))
+	assert.NoError(t, q.Push(2, obj3))
+
+	found, level, ok := q.Find("b")
+	assert.True(t, ok)
+	assert.Equal(t, obj2, found)
+	assert.Equal(t, 1, level)
+
+	_, _, ok = q.Find("unknown")
+	assert.False(t, ok)
+
+	ok = q.ChangePriority("b", 5)
+	assert.True(t, ok)
+
+	peeked, level, ok := q.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, obj2, peeked)
+	assert.Equal(t, 5, level)
+
+	removed, ok := q.Remove("b")
+	assert.True(t, ok)
+	assert.Equal(t, obj2, removed)
+	assert.Equal(t, 2, q.GetMaxPriority())
+
+	_, ok = q.Remove("b")
+	assert.False(t, ok)
+
+	assert.False(t, q.ChangePriority("b", 1))
+
+	removed, ok = q.Remove("c")
+	assert.True(t, ok)
+	assert.Equal(t, obj3, removed)
+
+	peeked, level, ok = q.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, obj1, peeked)
+	assert.Equal(t, 1, level)
+}
+
+func TestPriorityQueue_NegativeLevel(t *testing.T) {
+	q := priority.NewPriorityQueue[int, string]()
+
+	obj := &graph.Petri[int, string]{ID: "a"}
+	assert.NoError(t, q.Push(5, obj))
+
+	assert.True(t, q.ChangePriority("a", -1))
+
+	peeked, level, ok := q.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, obj, peeked)
+	assert.Equal(t, -1, level)
+
+	assert.NoError(t, q.Push(-3, &graph.Petri[int, string]{ID: "b"}))
+	assert.Equal(t, -1, q.GetMaxPriority())
+
+	_, ok = q.Remove("a")
+	assert.True(t, ok)
+	assert.Equal(t, -3, q.GetMaxPriority())
+}
+
+func TestPriorityQueue_PushDuplicate(t *testing.T) {
+	q := priority.NewPriorityQueue[int, string]()
+
+	obj := &graph.Petri[int, string]{ID: "a"}
+	assert.NoError(t, q.Push(1, obj))
+	assert.ErrorIs(t, q.Push(2, &graph.Petri[int, string]{ID: "a"}), priority.ErrDuplicateID)
+
+	found, level, ok := q.Find("a")
+	assert.True(t, ok)
+	assert.Equal(t, obj, found)
+	assert.Equal(t, 1, level)
+	assert.Equal(t, map[int]int{1: 1}, q.Depths())
+}
+
+func TestPriorityQueue_Range(t *testing.T) {
+	q := priority.NewPriorityQueue[int, string]()
+
+	assert.NoError(t, q.Push(1, &graph.Petri[int, string]{ID: "a"}))
+	assert.NoError(t, q.Push(3, &graph.Petri[int, string]{ID: "b"}))
+	assert.NoError(t, q.Push(1, &graph.Petri[int, string]{ID: "c"}))
+
+	var visited []string
+	q.Range(func(level int, obj *graph.Petri[int, string]) bool {
+		visited = append(visited, obj.ID)
+
+		return true
+	})
+
+	assert.Equal(t, []string{"b", "a", "c"}, visited)
+	assert.Equal(t, map[int]int{1: 2, 3: 1}, q.Depths())
+}
+
+func TestPriorityQueue_UnmarshalJSON(t *testing.T) {
+	q := priority.NewPriorityQueue[int, string]()
+	assert.NoError(t, q.Push(1, &graph.Petri[int, string]{ID: "a", Mailbox: []int{1, 2}}))
+	assert.NoError(t, q.Push(4, &graph.Petri[int, string]{ID: "b"}))
+
+	data, err := json.Marshal(q)
+	assert.NoError(t, err)
+
+	var restored priority.Queue[int, string]
+	err = json.Unmarshal(data, &restored)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, restored.GetMaxPriority())
+
+	found, level, ok := restored.Find("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, level)
+	assert.Equal(t, []int{1, 2}, found.Mailbox)
+
+	peeked, _, ok := restored.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, "b", peeked.ID)
+}
+
+func TestPriorityQueue_BuryRevive(t *testing.T) {
+	q := priority.NewPriorityQueue[int, string]()
+	assert.NoError(t, q.Push(2, &graph.Petri[int, string]{ID: "a"}))
+
+	dead, ok := q.Bury("a", errors.New("boom"))
+	assert.True(t, ok)
+	assert.Equal(t, 2, dead.Level)
+	assert.Equal(t, "boom", dead.Error)
+
+	_, _, ok = q.Peek()
+	assert.False(t, ok)
+	assert.Len(t, q.Dead, 1)
+
+	_, ok = q.Bury("a", nil)
+	assert.False(t, ok)
+
+	revived, ok := q.Revive("a")
+	assert.True(t, ok)
+	assert.Equal(t, "a", revived.Graph.ID)
+	assert.Empty(t, q.Dead)
+
+	_, level, ok := q.Find("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, level)
+}
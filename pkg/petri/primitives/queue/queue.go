@@ -46,6 +46,21 @@ func (q *Queue[T]) Peek() (*T, bool) {
 	return q.Elements[0], true
 }
 
+// Remove удаляет и возвращает первый элемент, удовлетворяющий условию
+func (q *Queue[T]) Remove(match func(*T) bool) (*T, bool) {
+	for i, val := range q.Elements {
+		if !match(val) {
+			continue
+		}
+
+		q.Elements = append(q.Elements[:i:i], q.Elements[i+1:]...)
+
+		return val, true
+	}
+
+	return nil, false
+}
+
 // IsEmpty проверяет, пуста ли очередь
 func (q *Queue[T]) IsEmpty() bool {
 	return len(q.Elements) == 0
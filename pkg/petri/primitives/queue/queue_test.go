@@ -69,3 +69,25 @@ func TestQueue_IsEmpty(t *testing.T) {
 	_, _ = q.Dequeue()
 	assert.True(t, q.IsEmpty())
 }
+
+func TestQueue_Remove(t *testing.T) {
+	q := queue.NewQueue[int]()
+
+	val1, val2, val3 := 1, 2, 3
+	q.Enqueue(&val1)
+	q.Enqueue(&val2)
+	q.Enqueue(&val3)
+
+	removed, ok := q.Remove(func(v *int) bool { return *v == 2 })
+	assert.True(t, ok)
+	assert.Equal(t, val2, *removed)
+
+	_, ok = q.Remove(func(v *int) bool { return *v == 2 })
+	assert.False(t, ok)
+
+	deqVal, _ := q.Dequeue()
+	assert.Equal(t, val1, *deqVal)
+
+	deqVal, _ = q.Dequeue()
+	assert.Equal(t, val3, *deqVal)
+}
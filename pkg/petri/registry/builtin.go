@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// Default returns a registry running nets without handler code: places route
+// a signal to the transition with the same ID, transitions move the token to
+// their first place and graph handlers do nothing. Register named handlers on
+// top of it.
+func Default[T any]() *Registry[T] {
+	return New[T]().
+		RegisterPlace("", Router[T]).
+		RegisterTransition("", Forward[T]).
+		RegisterGraph("", Noop)
+}
+
+// Router handles a place by choosing the transition whose ID equals the
+// signal formatted with fmt.Sprint. Other signals are ignored.
+func Router[T any](place *graph.Place[T, string]) (graph.PlaceHandler[T, string], error) {
+	return router[T]{place: place}, nil
+}
+
+// Forward handles a transition by moving the token to its first place.
+func Forward[T any](transition *graph.Transition[T, string]) (graph.TransitionHandler[T, string], error) {
+	places := transition.Places()
+	if len(places) == 0 {
+		return nil, fmt.Errorf("transition %s has no outgoing arc", transition.ID)
+	}
+
+	return forward[T]{to: places[0]}, nil
+}
+
+// Noop handles a graph without doing anything.
+func Noop(string) (graph.PetriHandler, error) {
+	return noop{}, nil
+}
+
+type router[T any] struct {
+	place *graph.Place[T, string]
+}
+
+func (r router[T]) HandleIn(*graph.Place[T, string]) error {
+	return nil
+}
+
+func (r router[T]) HandleOut(*graph.Place[T, string]) error {
+	return nil
+}
+
+func (r router[T]) ChooseTo(signal T) (*graph.Transition[T, string], error) {
+	id := fmt.Sprint(signal)
+	for _, t := range r.place.Transitions() {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("place %s, signal %s: %w", r.place.ID, id, graph.ErrSignalIgnored)
+}
+
+type forward[T any] struct {
+	to *graph.Place[T, string]
+}
+
+func (f forward[T]) Handle(*graph.Place[T, string], T) (*graph.Place[T, string], error) {
+	return f.to, nil
+}
+
+type noop struct{}
+
+func (noop) HandleIn() error {
+	return nil
+}
+
+func (noop) HandleOut() error {
+	return nil
+}
@@ -0,0 +1,265 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+var ErrInvalidNet = errors.New("invalid net")
+
+// Net describes the structure of a graph and the names of its handlers.
+// Current is empty for a graph that is not started.
+type Net struct {
+	ID          string
+	Handler     string
+	Places      []Node
+	Transitions []Node
+	Arcs        []Arc
+	Start       string
+	Finish      string
+	Current     string
+}
+
+// Node is a place or a transition. Timeout bounds each call of its handler,
+// Guard names the guard of a transition.
+type Node struct {
+	ID      string
+	Handler string
+	Guard   string
+	Timeout time.Duration
+}
+
+// Arc connects a place to a transition or a transition to a place.
+type Arc struct {
+	From string
+	To   string
+}
+
+// Build creates the graph described by the net, binds its handlers and
+// applies guards and timeouts. All problems found in the net are returned
+// together.
+func (r *Registry[T]) Build(n Net) (*graph.Petri[T, string], error) {
+	g, places, transitions, err := wire[T](n)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+
+	for _, node := range n.Places {
+		errs = append(errs, r.BindPlace(node.Handler, places[node.ID]))
+	}
+
+	for _, node := range n.Transitions {
+		errs = append(errs, r.BindTransition(node.Handler, transitions[node.ID]))
+	}
+
+	errs = append(errs, r.BindGraph(n.Handler, g))
+
+	guards, err := r.guardsOf(n)
+	errs = append(errs, err)
+
+	err = errors.Join(errs...)
+	if err != nil {
+		return nil, err
+	}
+
+	wrap(n, g, places, guards)
+
+	return g, nil
+}
+
+// Wire creates the structure of the net without handlers.
+func Wire[T any](n Net) (*graph.Petri[T, string], error) {
+	g, _, _, err := wire[T](n)
+
+	return g, err
+}
+
+func wire[T any](n Net) (*graph.Petri[T, string], map[string]*graph.Place[T, string], map[string]*graph.Transition[T, string], error) {
+	var errs []error
+
+	places := make(map[string]*graph.Place[T, string], len(n.Places))
+	for _, node := range n.Places {
+		if _, ok := places[node.ID]; ok {
+			errs = append(errs, fmt.Errorf("duplicate place %q", node.ID))
+		}
+
+		places[node.ID] = graph.NewPlace[T, string](node.ID, nil)
+	}
+
+	transitions := make(map[string]*graph.Transition[T, string], len(n.Transitions))
+	for _, node := range n.Transitions {
+		if _, ok := places[node.ID]; ok {
+			errs = append(errs, fmt.Errorf("transition %q has the ID of a place", node.ID))
+		}
+
+		if _, ok := transitions[node.ID]; ok {
+			errs = append(errs, fmt.Errorf("duplicate transition %q", node.ID))
+		}
+
+		transitions[node.ID] = graph.NewTransition[T, string](node.ID, nil)
+	}
+
+	for _, a := range n.Arcs {
+		if p, ok := places[a.From]; ok {
+			t, ok := transitions[a.To]
+			if !ok {
+				errs = append(errs, fmt.Errorf("arc %s -> %s: unknown transition %q", a.From, a.To, a.To))
+
+				continue
+			}
+
+			p.AddTransition(t)
+
+			continue
+		}
+
+		if t, ok := transitions[a.From]; ok {
+			p, ok := places[a.To]
+			if !ok {
+				errs = append(errs, fmt.Errorf("arc %s -> %s: unknown place %q", a.From, a.To, a.To))
+
+				continue
+			}
+
+			t.AddTo(p)
+
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("arc %s -> %s: unknown node %q", a.From, a.To, a.From))
+	}
+
+	g := graph.NewPetri[T, string](n.ID, nil)
+
+	for _, role := range []struct {
+		name string
+		id   string
+		set  func(*graph.Place[T, string]) *graph.Petri[T, string]
+	}{
+		{name: "start", id: n.Start, set: g.SetStartPlace},
+		{name: "finish", id: n.Finish, set: g.SetFinishPlace},
+		{name: "current", id: n.Current, set: g.SetCurrentPlace},
+	} {
+		if role.id == "" && role.name == "current" {
+			continue
+		}
+
+		p, ok := places[role.id]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s place %q is not defined", role.name, role.id))
+
+			continue
+		}
+
+		role.set(p)
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("net %s: %w: %w", n.ID, ErrInvalidNet, err)
+	}
+
+	return g, places, transitions, nil
+}
+
+// Describe returns the net of the graph. IDs are formatted with fmt.Sprint,
+// handler names are left empty.
+func Describe[T any, V comparable](g *graph.Petri[T, V]) Net {
+	n := Net{ID: fmt.Sprint(g.ID)}
+
+	places, transitions := g.Places(), g.Transitions()
+
+	for _, p := range places {
+		n.Places = append(n.Places, Node{ID: fmt.Sprint(p.ID)})
+
+		for _, t := range p.Transitions() {
+			n.Arcs = append(n.Arcs, Arc{From: fmt.Sprint(p.ID), To: fmt.Sprint(t.ID)})
+		}
+	}
+
+	for _, t := range transitions {
+		n.Transitions = append(n.Transitions, Node{ID: fmt.Sprint(t.ID)})
+
+		for _, p := range t.Places() {
+			n.Arcs = append(n.Arcs, Arc{From: fmt.Sprint(t.ID), To: fmt.Sprint(p.ID)})
+		}
+	}
+
+	if g.Start != nil {
+		n.Start = fmt.Sprint(g.Start.ID)
+	}
+
+	if g.Finish != nil {
+		n.Finish = fmt.Sprint(g.Finish.ID)
+	}
+
+	if g.Current != nil {
+		n.Current = fmt.Sprint(g.Current.ID)
+	}
+
+	return n
+}
+
+// guardsOf resolves the guards of transitions by name.
+func (r *Registry[T]) guardsOf(n Net) (map[string]Guard[T], error) {
+	var errs []error
+
+	guards := map[string]Guard[T]{}
+
+	for _, node := range n.Places {
+		if node.Guard != "" {
+			errs = append(errs, fmt.Errorf("place %s: guards apply to transitions only", node.ID))
+		}
+	}
+
+	for _, node := range n.Transitions {
+		if node.Guard == "" {
+			continue
+		}
+
+		guard, ok := r.guards[node.Guard]
+		if !ok {
+			errs = append(errs, fmt.Errorf("transition %s: %q: %w", node.ID, node.Guard, ErrUnknownGuard))
+
+			continue
+		}
+
+		guards[node.ID] = guard
+	}
+
+	return guards, errors.Join(errs...)
+}
+
+// wrap bounds handlers with timeouts and guards places leading to guarded
+// transitions.
+func wrap[T any](n Net, g *graph.Petri[T, string], places map[string]*graph.Place[T, string], guards map[string]Guard[T]) {
+	limits := map[string]time.Duration{}
+
+	for _, node := range append(slices.Clone(n.Places), n.Transitions...) {
+		if node.Timeout > 0 {
+			limits[node.ID] = node.Timeout
+		}
+	}
+
+	if len(limits) > 0 {
+		g.Use(timeouts[T](limits))
+	}
+
+	for _, node := range n.Places {
+		p := places[node.ID]
+
+		for _, t := range p.Transitions() {
+			if _, ok := guards[t.ID]; ok {
+				p.Handler = guardedPlace[T]{PlaceHandler: p.Handler, guards: guards}
+
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package registry binds handlers to graphs built from definitions by
+// handler name.
+package registry
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+var ErrUnknownHandler = errors.New("unknown handler")
+
+// PlaceFactory creates the handler of a place. It is called once the place
+// is wired, so the handler may rely on place.Transitions.
+type PlaceFactory[T any] func(place *graph.Place[T, string]) (graph.PlaceHandler[T, string], error)
+
+// TransitionFactory creates the handler of a transition. It is called once
+// the transition is wired, so the handler may rely on transition.Places.
+type TransitionFactory[T any] func(transition *graph.Transition[T, string]) (graph.TransitionHandler[T, string], error)
+
+// GraphFactory creates the handler of a graph.
+type GraphFactory func(id string) (graph.PetriHandler, error)
+
+// Registry maps handler names to factories. A factory registered with the
+// empty name is used for nodes whose handler name is not registered.
+type Registry[T any] struct {
+	places      map[string]PlaceFactory[T]
+	transitions map[string]TransitionFactory[T]
+	graphs      map[string]GraphFactory
+	guards      map[string]Guard[T]
+}
+
+func New[T any]() *Registry[T] {
+	return &Registry[T]{
+		places:      map[string]PlaceFactory[T]{},
+		transitions: map[string]TransitionFactory[T]{},
+		graphs:      map[string]GraphFactory{},
+		guards:      map[string]Guard[T]{},
+	}
+}
+
+func (r *Registry[T]) RegisterPlace(name string, factory PlaceFactory[T]) *Registry[T] {
+	r.places[name] = factory
+
+	return r
+}
+
+func (r *Registry[T]) RegisterTransition(name string, factory TransitionFactory[T]) *Registry[T] {
+	r.transitions[name] = factory
+
+	return r
+}
+
+func (r *Registry[T]) RegisterGraph(name string, factory GraphFactory) *Registry[T] {
+	r.graphs[name] = factory
+
+	return r
+}
+
+// BindPlace sets the handler of the place created by the named factory.
+func (r *Registry[T]) BindPlace(name string, place *graph.Place[T, string]) error {
+	factory, err := lookup(r.places, name)
+	if err != nil {
+		return fmt.Errorf("place %s: %w", place.ID, err)
+	}
+
+	handler, err := factory(place)
+	if err != nil {
+		return fmt.Errorf("place %s handler %q: %w", place.ID, name, err)
+	}
+
+	place.Handler = handler
+
+	return nil
+}
+
+// BindTransition sets the handler of the transition created by the named
+// factory.
+func (r *Registry[T]) BindTransition(name string, transition *graph.Transition[T, string]) error {
+	factory, err := lookup(r.transitions, name)
+	if err != nil {
+		return fmt.Errorf("transition %s: %w", transition.ID, err)
+	}
+
+	handler, err := factory(transition)
+	if err != nil {
+		return fmt.Errorf("transition %s handler %q: %w", transition.ID, name, err)
+	}
+
+	transition.Handler = handler
+
+	return nil
+}
+
+// BindGraph sets the handler of the graph created by the named factory.
+func (r *Registry[T]) BindGraph(name string, g *graph.Petri[T, string]) error {
+	factory, err := lookup(r.graphs, name)
+	if err != nil {
+		return fmt.Errorf("graph %s: %w", g.ID, err)
+	}
+
+	handler, err := factory(g.ID)
+	if err != nil {
+		return fmt.Errorf("graph %s handler %q: %w", g.ID, name, err)
+	}
+
+	g.Handler = handler
+
+	return nil
+}
+
+func lookup[F any](factories map[string]F, name string) (F, error) {
+	factory, ok := factories[name]
+	if ok {
+		return factory, nil
+	}
+
+	factory, ok = factories[""]
+	if ok {
+		return factory, nil
+	}
+
+	return factory, fmt.Errorf("%q: %w", name, ErrUnknownHandler)
+}
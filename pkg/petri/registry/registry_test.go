@@ -0,0 +1,124 @@
+package registry_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/registry"
+)
+
+func makeNet() registry.Net {
+	return registry.Net{
+		ID:          "order",
+		Places:      []registry.Node{{ID: "new"}, {ID: "paid", Handler: "audit"}, {ID: "done"}},
+		Transitions: []registry.Node{{ID: "pay"}, {ID: "ship"}},
+		Arcs: []registry.Arc{
+			{From: "new", To: "pay"},
+			{From: "pay", To: "paid"},
+			{From: "paid", To: "ship"},
+			{From: "ship", To: "done"},
+		},
+		Start:  "new",
+		Finish: "done",
+	}
+}
+
+type auditHandler struct {
+	graph.PlaceHandler[string, string]
+	entered int
+}
+
+func (h *auditHandler) HandleIn(from *graph.Place[string, string]) error {
+	h.entered++
+
+	return h.PlaceHandler.HandleIn(from)
+}
+
+func TestRegistry_Build(t *testing.T) {
+	audit := &auditHandler{}
+	reg := registry.Default[string]().
+		RegisterPlace("audit", func(place *graph.Place[string, string]) (graph.PlaceHandler[string, string], error) {
+			router, err := registry.Router(place)
+			audit.PlaceHandler = router
+
+			return audit, err
+		})
+
+	g, err := reg.Build(makeNet())
+	assert.NoError(t, err)
+
+	assert.NoError(t, g.Act("pay"))
+	assert.Equal(t, "paid", g.Current.ID)
+	assert.Equal(t, 1, audit.entered)
+
+	assert.ErrorIs(t, g.Act("pay"), graph.ErrSignalIgnored)
+	assert.NoError(t, g.Act("ship"))
+	assert.True(t, g.IsOnFinish())
+}
+
+func TestRegistry_Build_UnknownHandler(t *testing.T) {
+	reg := registry.New[string]().
+		RegisterPlace("", registry.Router[string]).
+		RegisterGraph("", registry.Noop)
+
+	_, err := reg.Build(makeNet())
+	assert.ErrorIs(t, err, registry.ErrUnknownHandler)
+	assert.ErrorContains(t, err, `transition pay: "": unknown handler`)
+	assert.ErrorContains(t, err, `transition ship: "": unknown handler`)
+}
+
+func TestRegistry_Build_InvalidNet(t *testing.T) {
+	n := makeNet()
+	n.Arcs = append(n.Arcs, registry.Arc{From: "new", To: "done"}, registry.Arc{From: "nowhere", To: "pay"})
+	n.Transitions = append(n.Transitions, registry.Node{ID: "new"})
+	n.Finish = "lost"
+
+	_, err := registry.Default[string]().Build(n)
+	assert.ErrorIs(t, err, registry.ErrInvalidNet)
+	assert.ErrorContains(t, err, `transition "new" has the ID of a place`)
+	assert.ErrorContains(t, err, `arc new -> done: unknown transition "done"`)
+	assert.ErrorContains(t, err, `arc nowhere -> pay: unknown node "nowhere"`)
+	assert.ErrorContains(t, err, `finish place "lost" is not defined`)
+}
+
+func TestRegistry_Build_FactoryError(t *testing.T) {
+	n := makeNet()
+	n.Arcs = n.Arcs[:3]
+
+	_, err := registry.Default[string]().Build(n)
+	assert.ErrorContains(t, err, "transition ship has no outgoing arc")
+}
+
+func TestDescribe(t *testing.T) {
+	g, err := registry.Default[string]().Build(makeNet())
+	assert.NoError(t, err)
+	assert.NoError(t, g.Act("pay"))
+
+	expected := makeNet()
+	expected.Places[1].Handler = ""
+	expected.Current = "paid"
+
+	result := registry.Describe(g)
+	assert.ElementsMatch(t, expected.Arcs, result.Arcs)
+
+	expected.Arcs, result.Arcs = nil, nil
+	assert.Equal(t, expected, result)
+}
+
+func TestRouter(t *testing.T) {
+	place := graph.NewPlace[int, string]("p", nil)
+	place.AddTransition(graph.NewTransition[int, string]("7", nil))
+
+	router, err := registry.Router(place)
+	assert.NoError(t, err)
+
+	chosen, err := router.ChooseTo(7)
+	assert.NoError(t, err)
+	assert.Equal(t, "7", chosen.ID)
+
+	_, err = router.ChooseTo(8)
+	assert.True(t, errors.Is(err, graph.ErrSignalIgnored))
+}
@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+var (
+	ErrUnknownGuard  = errors.New("unknown guard")
+	ErrGuardRejected = errors.New("guard rejected signal")
+	ErrTimeout       = errors.New("handler timed out")
+)
+
+// Guard tells whether a transition may fire on the signal.
+type Guard[T any] func(signal T) bool
+
+func (r *Registry[T]) RegisterGuard(name string, guard Guard[T]) *Registry[T] {
+	r.guards[name] = guard
+
+	return r
+}
+
+// guardedPlace refuses transitions whose guard rejects the signal. The
+// refusal wraps graph.ErrSignalIgnored, so the graph stays on the place.
+type guardedPlace[T any] struct {
+	graph.PlaceHandler[T, string]
+	guards map[string]Guard[T]
+}
+
+func (p guardedPlace[T]) ChooseTo(signal T) (*graph.Transition[T, string], error) {
+	return p.ChooseToContext(context.Background(), signal)
+}
+
+func (p guardedPlace[T]) HandleInContext(ctx context.Context, from *graph.Place[T, string]) error {
+	return graph.PlaceContextOf(p.PlaceHandler).HandleInContext(ctx, from)
+}
+
+func (p guardedPlace[T]) HandleOutContext(ctx context.Context, to *graph.Place[T, string]) error {
+	return graph.PlaceContextOf(p.PlaceHandler).HandleOutContext(ctx, to)
+}
+
+func (p guardedPlace[T]) ChooseToContext(ctx context.Context, signal T) (*graph.Transition[T, string], error) {
+	t, err := graph.PlaceContextOf(p.PlaceHandler).ChooseToContext(ctx, signal)
+	if err != nil || t == nil {
+		return t, err
+	}
+
+	guard, ok := p.guards[t.ID]
+	if ok && !guard(signal) {
+		return nil, fmt.Errorf("transition %s, signal %v: %w: %w", t.ID, signal, ErrGuardRejected, graph.ErrSignalIgnored)
+	}
+
+	return t, nil
+}
+
+// CompensateOut forwards to the guarded handler, so wrapping a place keeps
+// its compensation.
+func (p guardedPlace[T]) CompensateOut(to *graph.Place[T, string]) error {
+	compensator, ok := p.PlaceHandler.(graph.PlaceCompensator[T, string])
+	if !ok {
+		return nil
+	}
+
+	return compensator.CompensateOut(to)
+}
+
+// timeouts bounds handler calls of places and transitions with their
+// timeouts. It is an interceptor rather than a handler wrapper, so optional
+// handler interfaces stay visible to the graph.
+func timeouts[T any](limits map[string]time.Duration) graph.Interceptor[T, string] {
+	return func(ctx context.Context, call graph.Call[T, string], next func(context.Context) error) error {
+		timeout, ok := limits[call.NodeID]
+		if !ok {
+			return next(ctx)
+		}
+
+		switch call.Stage {
+		case graph.StageChooseTo, graph.StageHandle, graph.StagePlaceHandleIn,
+			graph.StagePlaceHandleOut, graph.StageCompensate:
+			return bounded(ctx, timeout, next)
+		default:
+			return next(ctx)
+		}
+	}
+}
+
+// bounded runs fn with a deadline and waits for it to return, so a handler is
+// never left running once the graph has moved on. Handlers see the deadline
+// in their context; one ignoring it runs to the end and fails with ErrTimeout
+// when it is late.
+func bounded(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := fn(ctx)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %v: %w", ErrTimeout, timeout, ctx.Err())
+	}
+
+	return err
+}
@@ -0,0 +1,195 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/registry"
+)
+
+type slowTransition struct {
+	next *graph.Place[string, string]
+}
+
+func (s slowTransition) HandleContext(ctx context.Context, _ *graph.Place[string, string], _ string) (*graph.Place[string, string], error) {
+	<-ctx.Done()
+
+	return s.next, ctx.Err()
+}
+
+type sleepyTransition struct {
+	next *graph.Place[string, string]
+	done *bool
+}
+
+func (s sleepyTransition) Handle(*graph.Place[string, string], string) (*graph.Place[string, string], error) {
+	time.Sleep(30 * time.Millisecond)
+	*s.done = true
+
+	return s.next, nil
+}
+
+type refundTransition struct {
+	graph.TransitionHandler[string, string]
+	log *[]string
+}
+
+func (r refundTransition) Compensate(from *graph.Place[string, string], _ string) error {
+	*r.log = append(*r.log, "refund from "+from.ID)
+
+	return nil
+}
+
+type releasePlace struct {
+	graph.PlaceHandler[string, string]
+	log *[]string
+}
+
+func (r releasePlace) CompensateOut(to *graph.Place[string, string]) error {
+	*r.log = append(*r.log, "release to "+to.ID)
+
+	return nil
+}
+
+type rejectingPlace struct {
+	graph.PlaceHandler[string, string]
+}
+
+func (rejectingPlace) HandleIn(*graph.Place[string, string]) error {
+	return errors.New("audit failed")
+}
+
+type panickingTransition struct{}
+
+func (panickingTransition) Handle(*graph.Place[string, string], string) (*graph.Place[string, string], error) {
+	panic("boom")
+}
+
+func TestRegistry_Build_Guard(t *testing.T) {
+	open := false
+
+	n := makeNet()
+	n.Transitions[0].Guard = "open"
+
+	reg := registry.Default[string]().RegisterGuard("open", func(string) bool { return open })
+
+	g, err := reg.Build(n)
+	assert.NoError(t, err)
+
+	err = g.Act("pay")
+	assert.ErrorIs(t, err, registry.ErrGuardRejected)
+	assert.ErrorIs(t, err, graph.ErrSignalIgnored)
+	assert.Equal(t, "new", g.Current.ID)
+
+	open = true
+	assert.NoError(t, g.Act("pay"))
+	assert.Equal(t, "paid", g.Current.ID)
+}
+
+func TestRegistry_Build_UnknownGuard(t *testing.T) {
+	n := makeNet()
+	n.Transitions[0].Guard = "missing"
+	n.Places[0].Guard = "open"
+
+	_, err := registry.Default[string]().Build(n)
+	assert.ErrorIs(t, err, registry.ErrUnknownGuard)
+	assert.ErrorContains(t, err, "place new: guards apply to transitions only")
+}
+
+func TestRegistry_Build_Timeout(t *testing.T) {
+	n := makeNet()
+	n.Transitions[0].Handler = "slow"
+	n.Transitions[0].Timeout = 10 * time.Millisecond
+
+	reg := registry.Default[string]().
+		RegisterTransition("slow", func(transition *graph.Transition[string, string]) (graph.TransitionHandler[string, string], error) {
+			return graph.AdaptTransitionHandler[string, string](slowTransition{next: transition.Places()[0]}), nil
+		})
+
+	g, err := reg.Build(n)
+	assert.NoError(t, err)
+
+	err = g.Act("pay")
+	assert.ErrorIs(t, err, registry.ErrTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, "new", g.Current.ID)
+}
+
+func TestRegistry_Build_TimeoutPanic(t *testing.T) {
+	n := makeNet()
+	n.Transitions[0].Handler = "panic"
+	n.Transitions[0].Timeout = time.Second
+
+	reg := registry.Default[string]().
+		RegisterTransition("panic", func(*graph.Transition[string, string]) (graph.TransitionHandler[string, string], error) {
+			return panickingTransition{}, nil
+		})
+
+	g, err := reg.Build(n)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, g.Act("pay"), graph.ErrPanic)
+}
+
+func TestRegistry_Build_TimeoutWaitsForHandler(t *testing.T) {
+	done := false
+
+	n := makeNet()
+	n.Transitions[0].Handler = "sleepy"
+	n.Transitions[0].Timeout = 5 * time.Millisecond
+
+	reg := registry.Default[string]().
+		RegisterTransition("sleepy", func(transition *graph.Transition[string, string]) (graph.TransitionHandler[string, string], error) {
+			return sleepyTransition{next: transition.Places()[0], done: &done}, nil
+		})
+
+	g, err := reg.Build(n)
+	assert.NoError(t, err)
+
+	err = g.Act("pay")
+	assert.ErrorIs(t, err, registry.ErrTimeout)
+	assert.True(t, done)
+	assert.Equal(t, "new", g.Current.ID)
+}
+
+func TestRegistry_Build_TimeoutKeepsCompensation(t *testing.T) {
+	var log []string
+
+	n := makeNet()
+	n.Places[0].Handler = "release"
+	n.Places[0].Timeout = time.Second
+	n.Places[1].Handler = "reject"
+	n.Transitions[0].Handler = "refund"
+	n.Transitions[0].Guard = "open"
+	n.Transitions[0].Timeout = time.Second
+
+	reg := registry.Default[string]().
+		RegisterGuard("open", func(string) bool { return true }).
+		RegisterPlace("release", func(place *graph.Place[string, string]) (graph.PlaceHandler[string, string], error) {
+			router, err := registry.Router(place)
+
+			return releasePlace{PlaceHandler: router, log: &log}, err
+		}).
+		RegisterPlace("reject", func(place *graph.Place[string, string]) (graph.PlaceHandler[string, string], error) {
+			router, err := registry.Router(place)
+
+			return rejectingPlace{PlaceHandler: router}, err
+		}).
+		RegisterTransition("refund", func(transition *graph.Transition[string, string]) (graph.TransitionHandler[string, string], error) {
+			forward, err := registry.Forward(transition)
+
+			return refundTransition{TransitionHandler: forward, log: &log}, err
+		})
+
+	g, err := reg.Build(n)
+	assert.NoError(t, err)
+
+	err = g.Act("pay")
+	assert.ErrorContains(t, err, "audit failed")
+	assert.Equal(t, "new", g.Current.ID)
+	assert.Equal(t, []string{"release to paid", "refund from new"}, log)
+}
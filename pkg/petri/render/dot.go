@@ -0,0 +1,134 @@
+// Package render draws graph definitions and their marking as diagrams.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+// token marks the current place of a graph.
+const token = "●"
+
+// DOT writes the graph in Graphviz DOT. Places are circles, transitions are
+// bars, the start place is highlighted, the finish place is a double circle
+// and the current place holds a token. WithHistory colors fired transitions.
+func DOT[T any, V comparable](w io.Writer, g *graph.Petri[T, V], opts ...Option) error {
+	b := strings.Builder{}
+
+	fmt.Fprintf(&b, "digraph %s {\n", quote(fmt.Sprint(g.ID)))
+	b.WriteString("\trankdir=LR;\n")
+	dotNet(&b, "\t", "", newDiagram(g, newOptions(opts)))
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// QueueDOT writes every queued graph in Graphviz DOT, grouped in clusters by
+// priority level from the highest one.
+func QueueDOT[T any, V comparable](w io.Writer, q *priority.Queue[T, V], opts ...Option) error {
+	o := newOptions(opts)
+
+	b := strings.Builder{}
+
+	b.WriteString("digraph queue {\n")
+	b.WriteString("\trankdir=LR;\n")
+
+	level, index := 0, 0
+	q.Range(func(l int, g *graph.Petri[T, V]) bool {
+		if index == 0 || l != level {
+			if index != 0 {
+				b.WriteString("\t}\n")
+			}
+
+			level = l
+			fmt.Fprintf(&b, "\tsubgraph %s {\n", quote("cluster_level_"+strconv.Itoa(l)))
+			fmt.Fprintf(&b, "\t\tlabel=%s;\n", quote("level "+strconv.Itoa(l)))
+		}
+
+		label := fmt.Sprint(g.ID)
+		if g.Suspended {
+			label += " (suspended)"
+		}
+
+		fmt.Fprintf(&b, "\t\tsubgraph %s {\n", quote("cluster_graph_"+strconv.Itoa(index)))
+		fmt.Fprintf(&b, "\t\t\tlabel=%s;\n", quote(label))
+		dotNet(&b, "\t\t\t", "g"+strconv.Itoa(index)+":", newDiagram(g, o))
+		b.WriteString("\t\t}\n")
+
+		index++
+
+		return true
+	})
+
+	if index != 0 {
+		b.WriteString("\t}\n")
+	}
+
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func dotNet(b *strings.Builder, indent, prefix string, d diagram) {
+	placeID := func(i int) string { return quote(prefix + "p:" + d.places[i].label) }
+	transitionID := func(i int) string { return quote(prefix + "t:" + d.transitions[i].label) }
+
+	for i, p := range d.places {
+		label := p.label
+		attrs := []string{"shape=circle"}
+
+		if p.finish {
+			attrs[0] = "shape=doublecircle"
+		}
+
+		if p.start {
+			attrs = append(attrs, "style=bold", "color=darkgreen")
+		}
+
+		if p.current {
+			label += "\n" + token
+		}
+
+		attrs = append(attrs, "label="+quote(label))
+		fmt.Fprintf(b, "%s%s [%s];\n", indent, placeID(i), strings.Join(attrs, ", "))
+	}
+
+	for i, t := range d.transitions {
+		color := "black"
+		if t.fired {
+			color = "red"
+		}
+
+		fmt.Fprintf(b, "%s%s [shape=box, style=filled, fillcolor=%s, width=0.1, height=0.5, label=\"\", xlabel=%s];\n",
+			indent, transitionID(i), color, quote(t.label))
+	}
+
+	for _, a := range d.arcs {
+		from, to := transitionID(a.transition), placeID(a.place)
+		if a.in {
+			from, to = to, from
+		}
+
+		attrs := ""
+		if a.fired {
+			attrs = " [color=red, penwidth=2]"
+		}
+
+		fmt.Fprintf(b, "%s%s -> %s%s;\n", indent, from, to, attrs)
+	}
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(s string) string {
+	return `"` + escaper.Replace(s) + `"`
+}
@@ -0,0 +1,107 @@
+package render_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+	"github.com/uzh13/GuePetri/pkg/petri/render"
+)
+
+// makeGraph builds start -> t1 -> middle -> t2 -> finish with the token on
+// the middle place.
+func makeGraph(id string) *graph.Petri[string, string] {
+	finish := graph.NewPlace[string, string]("finish", nil)
+	t2 := graph.NewTransition[string, string]("t2", nil).AddTo(finish)
+	middle := graph.NewPlace[string, string]("middle", nil).AddTransition(t2)
+	t1 := graph.NewTransition[string, string]("t1", nil).AddTo(middle)
+	start := graph.NewPlace[string, string]("start", nil).AddTransition(t1)
+
+	return graph.NewPetri[string, string](id, nil).
+		SetStartPlace(start).
+		SetFinishPlace(finish).
+		SetCurrentPlace(middle)
+}
+
+func TestDOT(t *testing.T) {
+	expected := `digraph "order" {
+	rankdir=LR;
+	"p:start" [shape=circle, style=bold, color=darkgreen, label="start"];
+	"p:middle" [shape=circle, label="middle\n●"];
+	"p:finish" [shape=doublecircle, label="finish"];
+	"t:t1" [shape=box, style=filled, fillcolor=black, width=0.1, height=0.5, label="", xlabel="t1"];
+	"t:t2" [shape=box, style=filled, fillcolor=black, width=0.1, height=0.5, label="", xlabel="t2"];
+	"p:start" -> "t:t1";
+	"p:middle" -> "t:t2";
+	"t:t1" -> "p:middle";
+	"t:t2" -> "p:finish";
+}
+`
+
+	b := bytes.Buffer{}
+	assert.NoError(t, render.DOT(&b, makeGraph("order")))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestDOT_FinishWithArcs(t *testing.T) {
+	expected := `digraph "loop" {
+	rankdir=LR;
+	"p:start" [shape=circle, style=bold, color=darkgreen, label="start"];
+	"p:finish" [shape=doublecircle, label="finish"];
+	"p:archive" [shape=circle, label="archive"];
+	"t:reopen" [shape=box, style=filled, fillcolor=black, width=0.1, height=0.5, label="", xlabel="reopen"];
+	"p:finish" -> "t:reopen";
+	"t:reopen" -> "p:archive";
+}
+`
+
+	archive := graph.NewPlace[string, string]("archive", nil)
+	reopen := graph.NewTransition[string, string]("reopen", nil).AddTo(archive)
+	finish := graph.NewPlace[string, string]("finish", nil).AddTransition(reopen)
+	g := graph.NewPetri[string, string]("loop", nil).
+		SetStartPlace(graph.NewPlace[string, string]("start", nil)).
+		SetFinishPlace(finish)
+
+	b := bytes.Buffer{}
+	assert.NoError(t, render.DOT(&b, g))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestDOT_WithHistory(t *testing.T) {
+	b := bytes.Buffer{}
+	assert.NoError(t, render.DOT(&b, makeFiredGraph(), render.WithHistory()))
+
+	result := b.String()
+	assert.Contains(t, result, `"t:t1" [shape=box, style=filled, fillcolor=red,`)
+	assert.Contains(t, result, `"p:start" -> "t:t1" [color=red, penwidth=2];`)
+	assert.Contains(t, result, `"p:middle" -> "t:t2";`)
+}
+
+func TestQueueDOT(t *testing.T) {
+	q := priority.NewPriorityQueue[string, string]()
+	assert.NoError(t, q.Push(0, makeGraph("low")))
+	assert.NoError(t, q.Push(2, makeGraph("high")))
+
+	suspended := makeGraph("waiting")
+	suspended.Suspended = true
+	assert.NoError(t, q.Push(0, suspended))
+
+	b := bytes.Buffer{}
+	assert.NoError(t, render.QueueDOT(&b, q))
+
+	result := b.String()
+	assert.Contains(t, result, `subgraph "cluster_level_2" {
+		label="level 2";
+		subgraph "cluster_graph_0" {
+			label="high";
+			"g0:p:start" [shape=circle, style=bold, color=darkgreen, label="start"];`)
+	assert.Contains(t, result, `	subgraph "cluster_level_0" {
+		label="level 0";
+		subgraph "cluster_graph_1" {
+			label="low";`)
+	assert.Contains(t, result, `label="waiting (suspended)";`)
+	assert.Contains(t, result, `"g2:t:t2" -> "g2:p:finish";`)
+}
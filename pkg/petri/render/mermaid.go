@@ -0,0 +1,105 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// Mermaid writes the graph as a Mermaid flowchart. Places are circles, the
+// finish place is a double circle and transitions are black boxes.
+func Mermaid[T any, V comparable](w io.Writer, g *graph.Petri[T, V], opts ...Option) error {
+	o := newOptions(opts)
+	d := newDiagram(g, o)
+	b := strings.Builder{}
+
+	b.WriteString("flowchart LR\n")
+
+	var start, current, fired []string
+
+	for i, p := range d.places {
+		id := "p" + strconv.Itoa(i)
+		label := p.label
+
+		if o.current && p.current {
+			label += " " + token
+			current = append(current, id)
+		}
+
+		if p.start {
+			start = append(start, id)
+		}
+
+		if p.finish {
+			fmt.Fprintf(&b, "\t%s(((%s)))\n", id, mermaidQuote(label))
+		} else {
+			fmt.Fprintf(&b, "\t%s((%s))\n", id, mermaidQuote(label))
+		}
+	}
+
+	transitions := make([]string, 0, len(d.transitions))
+	for i, t := range d.transitions {
+		id := "t" + strconv.Itoa(i)
+		transitions = append(transitions, id)
+
+		if t.fired {
+			fired = append(fired, id)
+		}
+
+		fmt.Fprintf(&b, "\t%s[%s]\n", id, mermaidQuote(t.label))
+	}
+
+	var firedArcs []string
+	for i, a := range d.arcs {
+		from, to := "t"+strconv.Itoa(a.transition), "p"+strconv.Itoa(a.place)
+		if a.in {
+			from, to = to, from
+		}
+
+		if a.fired {
+			firedArcs = append(firedArcs, strconv.Itoa(i))
+		}
+
+		fmt.Fprintf(&b, "\t%s --> %s\n", from, to)
+	}
+
+	b.WriteString("\tclassDef transition fill:#000,stroke:#000,color:#fff\n")
+	b.WriteString("\tclassDef start stroke:#060,stroke-width:3px\n")
+	mermaidClass(&b, "transition", transitions)
+	mermaidClass(&b, "start", start)
+
+	if len(current) != 0 {
+		b.WriteString("\tclassDef current fill:#fd0\n")
+		mermaidClass(&b, "current", current)
+	}
+
+	if len(fired) != 0 {
+		b.WriteString("\tclassDef fired fill:#c00,stroke:#c00\n")
+		mermaidClass(&b, "fired", fired)
+	}
+
+	if len(firedArcs) != 0 {
+		fmt.Fprintf(&b, "\tlinkStyle %s stroke:#c00,stroke-width:2px\n", strings.Join(firedArcs, ","))
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func mermaidClass(b *strings.Builder, class string, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\tclass %s %s\n", strings.Join(ids, ","), class)
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "\n", " ")
+
+func mermaidQuote(s string) string {
+	return `"` + mermaidEscaper.Replace(s) + `"`
+}
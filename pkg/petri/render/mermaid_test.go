@@ -0,0 +1,51 @@
+package render_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/render"
+)
+
+func makeFiredGraph() *graph.Petri[string, string] {
+	g := makeGraph("order")
+	g.Fired = []graph.Firing[string, string]{{Transition: "t1", From: "start", To: "middle"}}
+
+	return g
+}
+
+func TestMermaid(t *testing.T) {
+	expected := `flowchart LR
+	p0(("start"))
+	p1(("middle"))
+	p2((("finish")))
+	t0["t1"]
+	t1["t2"]
+	p0 --> t0
+	p1 --> t1
+	t0 --> p1
+	t1 --> p2
+	classDef transition fill:#000,stroke:#000,color:#fff
+	classDef start stroke:#060,stroke-width:3px
+	class t0,t1 transition
+	class p0 start
+`
+
+	b := bytes.Buffer{}
+	assert.NoError(t, render.Mermaid(&b, makeFiredGraph()))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestMermaid_CurrentAndHistory(t *testing.T) {
+	b := bytes.Buffer{}
+	assert.NoError(t, render.Mermaid(&b, makeFiredGraph(), render.WithCurrent(), render.WithHistory()))
+
+	result := b.String()
+	assert.Contains(t, result, "\tp1((\"middle ●\"))\n")
+	assert.Contains(t, result, "\tclass p1 current\n")
+	assert.Contains(t, result, "\tclass t0 fired\n")
+	assert.Contains(t, result, "\tlinkStyle 0,2 stroke:#c00,stroke-width:2px\n")
+}
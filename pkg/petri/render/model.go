@@ -0,0 +1,115 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// diagram is a graph reduced to what the exporters draw. Labels are the
+// place and transition IDs.
+type diagram struct {
+	places      []node
+	transitions []node
+	arcs        []edge
+}
+
+type node struct {
+	label   string
+	start   bool
+	finish  bool
+	current bool
+	fired   bool
+}
+
+// edge connects places[place] and transitions[transition], from the place
+// when in is set.
+type edge struct {
+	place      int
+	transition int
+	in         bool
+	fired      bool
+}
+
+func newDiagram[T any, V comparable](g *graph.Petri[T, V], o options) diagram {
+	firedTransitions, firedArcs := fired(g, o)
+
+	d := diagram{}
+	placeIndex := map[V]int{}
+	transitionIndex := map[V]int{}
+
+	places, transitions := nodes(g)
+
+	for _, p := range places {
+		placeIndex[p.ID] = len(d.places)
+		d.places = append(d.places, node{
+			label:   fmt.Sprint(p.ID),
+			start:   g.Start != nil && p.ID == g.Start.ID,
+			finish:  g.Finish != nil && p.ID == g.Finish.ID,
+			current: g.Current != nil && p.ID == g.Current.ID,
+		})
+	}
+
+	for _, t := range transitions {
+		label := fmt.Sprint(t.ID)
+		_, ok := firedTransitions[label]
+
+		transitionIndex[t.ID] = len(d.transitions)
+		d.transitions = append(d.transitions, node{label: label, fired: ok})
+	}
+
+	for _, p := range places {
+		for _, t := range p.Transitions() {
+			_, ok := firedArcs[arc{place: fmt.Sprint(p.ID), transition: fmt.Sprint(t.ID), in: true}]
+			d.arcs = append(d.arcs, edge{place: placeIndex[p.ID], transition: transitionIndex[t.ID], in: true, fired: ok})
+		}
+	}
+
+	for _, t := range transitions {
+		for _, p := range t.Places() {
+			_, ok := firedArcs[arc{place: fmt.Sprint(p.ID), transition: fmt.Sprint(t.ID)}]
+			d.arcs = append(d.arcs, edge{place: placeIndex[p.ID], transition: transitionIndex[t.ID], fired: ok})
+		}
+	}
+
+	return d
+}
+
+// nodes returns the places and transitions of the graph. Walking the graph
+// stops at the finish and current places, so the arcs leaving them are
+// followed here to draw every node an arc points to.
+func nodes[T any, V comparable](g *graph.Petri[T, V]) ([]*graph.Place[T, V], []*graph.Transition[T, V]) {
+	places, transitions := g.Places(), g.Transitions()
+
+	seenPlaces := make(map[V]struct{}, len(places))
+	for _, p := range places {
+		seenPlaces[p.ID] = struct{}{}
+	}
+
+	seenTransitions := make(map[V]struct{}, len(transitions))
+	for _, t := range transitions {
+		seenTransitions[t.ID] = struct{}{}
+	}
+
+	for i := 0; i < len(places); i++ {
+		for _, t := range places[i].Transitions() {
+			if _, ok := seenTransitions[t.ID]; ok {
+				continue
+			}
+
+			seenTransitions[t.ID] = struct{}{}
+			transitions = append(transitions, t)
+
+			for _, p := range t.Places() {
+				if _, ok := seenPlaces[p.ID]; ok {
+					continue
+				}
+
+				seenPlaces[p.ID] = struct{}{}
+				places = append(places, p)
+			}
+		}
+	}
+
+	return places, transitions
+}
@@ -0,0 +1,65 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// Option changes what a diagram shows.
+type Option func(*options)
+
+type options struct {
+	current bool
+	history bool
+}
+
+// WithCurrent highlights the current place. DOT always shows it as a token.
+func WithCurrent() Option {
+	return func(o *options) {
+		o.current = true
+	}
+}
+
+// WithHistory highlights transitions fired by the graph and their arcs.
+func WithHistory() Option {
+	return func(o *options) {
+		o.history = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// arc is a directed edge between a place and a transition, from the place
+// when in is set.
+type arc struct {
+	place      string
+	transition string
+	in         bool
+}
+
+// fired returns transitions and arcs of the committed firings of the graph.
+func fired[T any, V comparable](g *graph.Petri[T, V], o options) (map[string]struct{}, map[arc]struct{}) {
+	transitions := map[string]struct{}{}
+	arcs := map[arc]struct{}{}
+
+	if !o.history {
+		return transitions, arcs
+	}
+
+	for _, f := range g.Fired {
+		transition := fmt.Sprint(f.Transition)
+		transitions[transition] = struct{}{}
+		arcs[arc{place: fmt.Sprint(f.From), transition: transition, in: true}] = struct{}{}
+		arcs[arc{place: fmt.Sprint(f.To), transition: transition}] = struct{}{}
+	}
+
+	return transitions, arcs
+}
@@ -0,0 +1,94 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+// PlantUML writes the graph as a PlantUML diagram. Places are circles with
+// the finish place drawn bold, transitions are black rectangles.
+func PlantUML[T any, V comparable](w io.Writer, g *graph.Petri[T, V], opts ...Option) error {
+	o := newOptions(opts)
+	d := newDiagram(g, o)
+	b := strings.Builder{}
+
+	fmt.Fprintf(&b, "@startuml %s\n", plantUMLName(fmt.Sprint(g.ID)))
+	b.WriteString("left to right direction\n")
+
+	for i, p := range d.places {
+		label := p.label
+		var style []string
+
+		if o.current && p.current {
+			label += "\n" + token
+			style = append(style, "gold")
+		}
+
+		if p.start {
+			style = append(style, "line:green")
+		}
+
+		if p.finish {
+			style = append(style, "line.bold")
+		}
+
+		fmt.Fprintf(&b, "circle %s as p%d%s\n", plantUMLQuote(label), i, plantUMLStyle(style))
+	}
+
+	for i, t := range d.transitions {
+		style := []string{"black", "text:white"}
+		if t.fired {
+			style[0] = "red"
+		}
+
+		fmt.Fprintf(&b, "rectangle %s as t%d%s\n", plantUMLQuote(t.label), i, plantUMLStyle(style))
+	}
+
+	for _, a := range d.arcs {
+		from, to := "t"+strconv.Itoa(a.transition), "p"+strconv.Itoa(a.place)
+		if a.in {
+			from, to = to, from
+		}
+
+		link := "-->"
+		if a.fired {
+			link = "-[#red,bold]->"
+		}
+
+		fmt.Fprintf(&b, "%s %s %s\n", from, link, to)
+	}
+
+	b.WriteString("@enduml\n")
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func plantUMLStyle(style []string) string {
+	if len(style) == 0 {
+		return ""
+	}
+
+	return " #" + strings.Join(style, ";")
+}
+
+var plantUMLEscaper = strings.NewReplacer(`"`, `'`, "\n", `\n`)
+
+func plantUMLQuote(s string) string {
+	return `"` + plantUMLEscaper.Replace(s) + `"`
+}
+
+func plantUMLName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\n' || r == '\t' {
+			return '_'
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,30 @@
+package render_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/render"
+)
+
+func TestPlantUML(t *testing.T) {
+	expected := `@startuml order
+left to right direction
+circle "start" as p0 #line:green
+circle "middle\n●" as p1 #gold
+circle "finish" as p2 #line.bold
+rectangle "t1" as t0 #red;text:white
+rectangle "t2" as t1 #black;text:white
+p0 -[#red,bold]-> t0
+p1 --> t1
+t0 -[#red,bold]-> p1
+t1 --> p2
+@enduml
+`
+
+	b := bytes.Buffer{}
+	assert.NoError(t, render.PlantUML(&b, makeFiredGraph(), render.WithCurrent(), render.WithHistory()))
+	assert.Equal(t, expected, b.String())
+}
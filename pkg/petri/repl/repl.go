@@ -0,0 +1,420 @@
+// Package repl steps through a queue loaded from a storage for debugging.
+//
+// Graphs keep only their state in a storage, so the structure and handlers
+// come from definitions: a graph gets the definition with the same ID, or the
+// only one given. Handlers are the defaults of the registry package, a place
+// fires the transition named as the signal.
+package repl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/definition"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+var (
+	ErrNoDefinition   = errors.New("no definition for graph")
+	ErrNothingToUndo  = errors.New("nothing to undo")
+	ErrUnknownCommand = errors.New("unknown command")
+)
+
+const help = `commands:
+  ls                   list graphs by level and dead letters
+  show [GRAPH]         current place and enabled transitions, of the head graph by default
+  send SIGNAL [GRAPH]  deliver a signal to the queue or to a graph
+  undo                 revert the last send
+  load                 reload the state from the storage, dropping undo steps
+  save                 write the state back to the storage
+  dump [FILE]          print the state as JSON or write it to the file
+  help                 show this help
+  quit                 leave
+`
+
+// Session is a queue of one storage ID under inspection.
+type Session struct {
+	builder *aggregate.Builder[string, string, string]
+	defs    map[string]definition.Document
+	queue   *aggregate.PetriQueue[string, string]
+	undo    [][]byte
+	out     io.Writer
+}
+
+func New(storage aggregate.Storage[string, string, string], id string, defs []definition.Document, out io.Writer) *Session {
+	s := &Session{
+		builder: aggregate.NewBuilder[string, string, string](id, storage),
+		defs:    make(map[string]definition.Document, len(defs)),
+		out:     out,
+	}
+
+	for _, d := range defs {
+		s.defs[d.ID] = d
+	}
+
+	return s
+}
+
+// Load reads the queue from the storage.
+func (s *Session) Load() error {
+	err := s.builder.LoadState()
+	if err != nil {
+		return err
+	}
+
+	// a targeted send runs the graph in place, as the queue order is not
+	// what the session explores
+	q := s.builder.Build("").SetOffTurnPolicy(aggregate.OffTurnRun)
+
+	err = s.attach(q.GetQueue())
+	if err != nil {
+		return err
+	}
+
+	s.queue = q
+	s.undo = nil
+
+	return nil
+}
+
+// Run executes commands read line by line until quit or the end of input.
+// Failed commands are reported and do not stop the session.
+func (s *Session) Run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+
+	for {
+		fmt.Fprint(s.out, "> ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(s.out)
+
+			return scanner.Err()
+		}
+
+		quit, err := s.Exec(scanner.Text())
+		if err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+		}
+
+		if quit {
+			return nil
+		}
+	}
+}
+
+// Exec executes one command line.
+func (s *Session) Exec(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+
+	args := fields[1:]
+
+	var err error
+	switch fields[0] {
+	case "ls":
+		s.list()
+	case "show":
+		err = s.show(args)
+	case "send":
+		err = s.send(args)
+	case "undo":
+		err = s.revert()
+	case "load":
+		err = s.Load()
+	case "save":
+		err = s.builder.SaveState(s.queue)
+	case "dump":
+		err = s.dump(args)
+	case "help":
+		fmt.Fprint(s.out, help)
+	case "quit", "exit":
+		return true, nil
+	default:
+		err = fmt.Errorf("%q: %w, try help", fields[0], ErrUnknownCommand)
+	}
+
+	return false, err
+}
+
+func (s *Session) list() {
+	q := s.queue.GetQueue()
+	head, _, _ := q.Peek()
+
+	level, first := 0, true
+	q.Range(func(l int, g *graph.Petri[string, string]) bool {
+		if first || l != level {
+			fmt.Fprintf(s.out, "level %d\n", l)
+			level, first = l, false
+		}
+
+		mark := " "
+		if g == head {
+			mark = "*"
+		}
+
+		fmt.Fprintf(s.out, "  %s %s  %s\n", mark, g.ID, state(g))
+
+		return true
+	})
+
+	if first {
+		fmt.Fprintln(s.out, "queue is empty")
+	}
+
+	if len(q.Dead) > 0 {
+		fmt.Fprintln(s.out, "dead letters")
+	}
+
+	for _, dead := range q.Dead {
+		fmt.Fprintf(s.out, "    %s  level %d: %s\n", dead.Graph.ID, dead.Level, dead.Error)
+	}
+}
+
+func (s *Session) show(args []string) error {
+	g, level, err := s.target(args)
+	if err != nil {
+		return err
+	}
+
+	place := g.Current
+	if place == nil {
+		place = g.Start
+	}
+
+	enabled := make([]string, 0, len(place.Transitions()))
+	for _, t := range place.Transitions() {
+		enabled = append(enabled, t.ID)
+	}
+
+	fmt.Fprintf(s.out, "graph    %s\n", g.ID)
+	fmt.Fprintf(s.out, "level    %d\n", level)
+	fmt.Fprintf(s.out, "place    %s\n", state(g))
+	fmt.Fprintf(s.out, "enabled  %s\n", strings.Join(enabled, ", "))
+
+	if len(g.Mailbox) > 0 {
+		fmt.Fprintf(s.out, "mailbox  %s\n", strings.Join(g.Mailbox, ", "))
+	}
+
+	if len(g.Fired) > 0 {
+		f := g.Fired[len(g.Fired)-1]
+		fmt.Fprintf(s.out, "last     %s: %s -> %s -> %s\n", f.Signal, f.From, f.Transition, f.To)
+	}
+
+	return nil
+}
+
+// target returns the named graph or the head of the queue.
+func (s *Session) target(args []string) (*graph.Petri[string, string], int, error) {
+	if len(args) > 0 {
+		g, level, ok := s.queue.FindGraph(args[0])
+		if !ok {
+			return nil, 0, fmt.Errorf("graph %s: %w", args[0], aggregate.ErrGraphNotFound)
+		}
+
+		return g, level, nil
+	}
+
+	g, level, ok := s.queue.GetQueue().Peek()
+	if !ok {
+		return nil, 0, aggregate.ErrQueueEmpty
+	}
+
+	return g, level, nil
+}
+
+func (s *Session) send(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("usage: send SIGNAL [GRAPH]")
+	}
+
+	if _, _, ok := s.queue.GetQueue().Peek(); !ok {
+		return aggregate.ErrQueueEmpty
+	}
+
+	snapshot, err := json.Marshal(s.queue.GetQueue())
+	if err != nil {
+		return fmt.Errorf("taking snapshot: %w", err)
+	}
+
+	before := position(s.queue.GetQueue())
+
+	if len(args) == 2 {
+		err = s.queue.ActOn(args[1], args[0])
+	} else {
+		var report *aggregate.ActReport[string]
+
+		report, err = s.queue.ActReport(args[0])
+		if report != nil && len(report.Completed) > 0 {
+			fmt.Fprintf(s.out, "completed      %s\n", strings.Join(report.Completed, ", "))
+		}
+
+		if report != nil && len(report.DeadLettered) > 0 {
+			fmt.Fprintf(s.out, "dead lettered  %s\n", strings.Join(report.DeadLettered, ", "))
+		}
+	}
+
+	// An ignored or failed send is an undo step only when it moved a graph on
+	// the way, as the zero signal after a completed graph does.
+	if err == nil || position(s.queue.GetQueue()) != before {
+		s.undo = append(s.undo, snapshot)
+	}
+
+	ignored := errors.Is(err, graph.ErrSignalIgnored)
+	if err != nil && !ignored {
+		return err
+	}
+
+	// The signal is ignored by the graph it was sent to, the head one unless
+	// the send is targeted.
+	g, _, ok := s.queue.GetQueue().Peek()
+	if ignored {
+		by := g
+		if len(args) == 2 {
+			if target, _, found := s.queue.FindGraph(args[1]); found {
+				by = target
+			}
+		}
+
+		fmt.Fprintf(s.out, "ignored by     %s\n", by.ID)
+	}
+
+	if ok {
+		fmt.Fprintf(s.out, "head           %s  %s\n", g.ID, state(g))
+	}
+
+	return nil
+}
+
+// revert restores the state taken before the last send.
+func (s *Session) revert() error {
+	if len(s.undo) == 0 {
+		return ErrNothingToUndo
+	}
+
+	snapshot := s.undo[len(s.undo)-1]
+
+	q := priority.NewPriorityQueue[string, string]()
+
+	err := json.Unmarshal(snapshot, q)
+	if err != nil {
+		return fmt.Errorf("restoring snapshot: %w", err)
+	}
+
+	err = s.attach(q)
+	if err != nil {
+		return err
+	}
+
+	s.queue = aggregate.NewPetriQueue(q, "").SetOffTurnPolicy(aggregate.OffTurnRun)
+	s.undo = s.undo[:len(s.undo)-1]
+
+	return nil
+}
+
+func (s *Session) dump(args []string) error {
+	data, err := json.MarshalIndent(s.queue.GetQueue(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("dumping state: %w", err)
+	}
+
+	data = append(data, '\n')
+
+	if len(args) == 0 {
+		_, err = s.out.Write(data)
+
+		return err
+	}
+
+	return os.WriteFile(args[0], data, 0o644)
+}
+
+// attach gives queued and dead lettered graphs the structure and handlers of
+// their definitions.
+func (s *Session) attach(q *priority.Queue[string, string]) error {
+	var errs []error
+
+	q.Range(func(_ int, g *graph.Petri[string, string]) bool {
+		errs = append(errs, s.bind(g))
+
+		return true
+	})
+
+	for _, dead := range q.Dead {
+		errs = append(errs, s.bind(dead.Graph))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Session) bind(g *graph.Petri[string, string]) error {
+	d, ok := s.defs[g.ID]
+	if !ok && len(s.defs) == 1 {
+		for _, only := range s.defs {
+			d, ok = only, true
+		}
+	}
+
+	if !ok {
+		return fmt.Errorf("graph %s: %w", g.ID, ErrNoDefinition)
+	}
+
+	built, err := definition.Build(d)
+	if err != nil {
+		return fmt.Errorf("graph %s: %w", g.ID, err)
+	}
+
+	g.Handler = built.Handler
+	g.Start = built.Start
+	g.Finish = built.Finish
+
+	if g.Current == nil {
+		return nil
+	}
+
+	current, ok := built.Place(g.Current.ID)
+	if !ok {
+		return fmt.Errorf("graph %s: current place %s is not in definition %s", g.ID, g.Current.ID, d.ID)
+	}
+
+	g.Current = current
+
+	return nil
+}
+
+// position describes where the graphs of the queue are. History is left out,
+// it grows with every attempt including ignored ones.
+func position(q *priority.Queue[string, string]) string {
+	b := strings.Builder{}
+
+	q.Range(func(level int, g *graph.Petri[string, string]) bool {
+		fmt.Fprintf(&b, "%d %s %s %v %d\n", level, g.ID, state(g), g.Mailbox, len(g.Fired))
+
+		return true
+	})
+
+	for _, dead := range q.Dead {
+		fmt.Fprintf(&b, "dead %s\n", dead.Graph.ID)
+	}
+
+	return b.String()
+}
+
+func state(g *graph.Petri[string, string]) string {
+	switch {
+	case g.Current == nil:
+		return "not started"
+	case g.Suspended:
+		return g.Current.ID + " (suspended)"
+	default:
+		return g.Current.ID
+	}
+}
@@ -0,0 +1,186 @@
+package repl_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/definition"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+	"github.com/uzh13/GuePetri/pkg/petri/repl"
+)
+
+const orderYAML = `id: order
+start: new
+finish: done
+places:
+  - id: new
+  - id: paid
+  - id: done
+transitions:
+  - id: pay
+  - id: ship
+arcs:
+  - {from: new, to: pay}
+  - {from: pay, to: paid}
+  - {from: paid, to: ship}
+  - {from: ship, to: done}
+`
+
+// makeStorage stores order-1 suspended on paid and order-2 started on a
+// higher level.
+func makeStorage(t *testing.T) (aggregate.Storage[string, string, string], definition.Document) {
+	t.Helper()
+
+	d, err := definition.Decode(strings.NewReader(orderYAML), definition.YAML)
+	assert.NoError(t, err)
+
+	q := aggregate.NewPetriQueue(priority.NewPriorityQueue[string, string](), "")
+
+	for _, g := range []struct {
+		id    string
+		level int
+	}{{id: "order-1", level: 1}, {id: "order-2", level: 5}} {
+		built, err := definition.Build(d)
+		assert.NoError(t, err)
+
+		built.ID = g.id
+		assert.NoError(t, q.AddGraph(g.level, built))
+
+		if g.id == "order-1" {
+			assert.NoError(t, q.Act("pay"))
+		}
+	}
+
+	storage, err := repl.Open("dir", t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, storage.(aggregate.Saver[string, string, string]).Save("user1", q.GetQueue()))
+
+	return storage, d
+}
+
+func TestSession_Run(t *testing.T) {
+	storage, d := makeStorage(t)
+
+	out := bytes.Buffer{}
+	s := repl.New(storage, "user1", []definition.Document{d}, &out)
+	assert.NoError(t, s.Load())
+
+	script := "ls\nshow\nsend pay\nsend ship\nls\nundo\nshow order-2\nundo\nundo\nbogus\nquit\nls\n"
+	assert.NoError(t, s.Run(strings.NewReader(script)))
+	assert.Equal(t, `> level 5
+  * order-2  new
+level 1
+    order-1  paid (suspended)
+> graph    order-2
+level    5
+place    new
+enabled  pay
+> head           order-2  paid
+> completed      order-2
+head           order-1  paid
+> level 1
+  * order-1  paid
+> > graph    order-2
+level    5
+place    paid
+enabled  ship
+last     pay: new -> pay -> paid
+> > error: nothing to undo
+> error: "bogus": unknown command, try help
+> `, out.String())
+}
+
+func TestSession_Run_Targeted(t *testing.T) {
+	storage, d := makeStorage(t)
+
+	out := bytes.Buffer{}
+	s := repl.New(storage, "user1", []definition.Document{d}, &out)
+	assert.NoError(t, s.Load())
+
+	script := "send pay order-1\nsend ship order-1\nls\nundo\nls\nundo\nquit\n"
+	assert.NoError(t, s.Run(strings.NewReader(script)))
+	assert.Equal(t, `> ignored by     order-1
+head           order-2  new
+> head           order-2  new
+> level 5
+  * order-2  new
+> > level 5
+  * order-2  new
+level 1
+    order-1  paid (suspended)
+> error: nothing to undo
+> `, out.String())
+}
+
+func TestSession_SaveAndDump(t *testing.T) {
+	storage, d := makeStorage(t)
+
+	s := repl.New(storage, "user1", []definition.Document{d}, &bytes.Buffer{})
+	assert.NoError(t, s.Load())
+
+	dump := filepath.Join(t.TempDir(), "state.json")
+	for _, line := range []string{"send pay", "save", "dump " + dump} {
+		_, err := s.Exec(line)
+		assert.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(dump)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"transition": "pay"`)
+
+	out := bytes.Buffer{}
+	reloaded := repl.New(storage, "user1", []definition.Document{d}, &out)
+	assert.NoError(t, reloaded.Load())
+
+	_, err = reloaded.Exec("show order-2")
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "place    paid\n")
+
+	_, err = reloaded.Exec("undo")
+	assert.ErrorIs(t, err, repl.ErrNothingToUndo)
+}
+
+func TestSession_Load(t *testing.T) {
+	storage, d := makeStorage(t)
+
+	other := d
+	other.ID = "refund"
+
+	err := repl.New(storage, "user1", []definition.Document{d, other}, &bytes.Buffer{}).Load()
+	assert.ErrorIs(t, err, repl.ErrNoDefinition)
+
+	d.Places = d.Places[1:]
+	d.Start = "paid"
+	d.Transitions = d.Transitions[1:]
+	d.Arcs = d.Arcs[2:]
+
+	err = repl.New(storage, "user1", []definition.Document{d}, &bytes.Buffer{}).Load()
+	assert.ErrorContains(t, err, "graph order-2: current place new is not in definition order")
+}
+
+func TestOpen(t *testing.T) {
+	_, err := repl.Open("redis", "localhost")
+	assert.ErrorIs(t, err, repl.ErrUnknownStorage)
+
+	storage, err := repl.Open("file", filepath.Join(t.TempDir(), "missing.json"))
+	assert.NoError(t, err)
+
+	out := bytes.Buffer{}
+	s := repl.New(storage, "user1", nil, &out)
+	assert.NoError(t, s.Load())
+
+	_, err = s.Exec("ls")
+	assert.NoError(t, err)
+	assert.Equal(t, "queue is empty\n", out.String())
+
+	_, err = s.Exec("send pay")
+	assert.ErrorIs(t, err, aggregate.ErrQueueEmpty)
+
+	assert.Equal(t, []string{"dir", "file"}, repl.Storages())
+}
@@ -0,0 +1,103 @@
+package repl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+)
+
+var ErrUnknownStorage = errors.New("unknown storage")
+
+// Opener opens a storage by its data source name.
+type Opener func(dsn string) (aggregate.Storage[string, string, string], error)
+
+var openers = map[string]Opener{
+	"file": func(dsn string) (aggregate.Storage[string, string, string], error) {
+		return fileStorage{path: func(string) string { return dsn }}, nil
+	},
+	"dir": func(dsn string) (aggregate.Storage[string, string, string], error) {
+		return fileStorage{path: func(id string) string { return filepath.Join(dsn, id+".json") }}, nil
+	},
+}
+
+// Register makes a storage available to Open under the name. Storages "file",
+// a JSON file holding the queue of any ID, and "dir", a directory with a JSON
+// file per ID, are registered out of the box.
+func Register(name string, open Opener) {
+	openers[name] = open
+}
+
+// Storages returns the names of registered storages.
+func Storages() []string {
+	return slices.Sorted(maps.Keys(openers))
+}
+
+// Open opens the storage registered under the name.
+func Open(name, dsn string) (aggregate.Storage[string, string, string], error) {
+	open, ok := openers[name]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", name, ErrUnknownStorage)
+	}
+
+	return open(dsn)
+}
+
+// fileStorage keeps queues as JSON files. A missing file is an empty queue.
+type fileStorage struct {
+	path func(id string) string
+}
+
+func (f fileStorage) Get(id string) (*priority.Queue[string, string], error) {
+	data, err := os.ReadFile(f.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	q := priority.NewPriorityQueue[string, string]()
+
+	err = json.Unmarshal(data, q)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", f.path(id), err)
+	}
+
+	return q, nil
+}
+
+// Save replaces the file at once, so a failed write keeps the former state.
+func (f fileStorage) Save(id string, q *priority.Queue[string, string]) error {
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := f.path(id)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,114 @@
+// Package tracing reports graph execution as OpenTelemetry spans. Every
+// signal delivered to a PetriQueue gets a span with a child span for each
+// handler call made on the way.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+)
+
+const instrumentation = "github.com/uzh13/GuePetri/pkg/petri/tracing"
+
+const (
+	AttrSignal       = attribute.Key("petri.signal")
+	AttrGraphID      = attribute.Key("petri.graph.id")
+	AttrNodeID       = attribute.Key("petri.node.id")
+	AttrStage        = attribute.Key("petri.stage")
+	AttrPriority     = attribute.Key("petri.priority")
+	AttrCompleted    = attribute.Key("petri.completed")
+	AttrDeadLettered = attribute.Key("petri.dead_lettered")
+	AttrCascade      = attribute.Key("petri.cascade")
+)
+
+// Instrument traces every Act of the queue and every handler call of its
+// graphs. A nil provider means the global one.
+func Instrument[T any, V comparable](q *aggregate.PetriQueue[T, V], provider trace.TracerProvider) *aggregate.PetriQueue[T, V] {
+	return q.UseAct(Act[T, V](provider)).Use(Interceptor[T, V](provider))
+}
+
+// Act returns a queue interceptor starting the span of a delivered signal.
+func Act[T any, V comparable](provider trace.TracerProvider) aggregate.ActInterceptor[T, V] {
+	tracer := tracerOf(provider)
+
+	return func(ctx context.Context, signal T, next func(context.Context) (*aggregate.ActReport[V], error)) (*aggregate.ActReport[V], error) {
+		ctx, span := tracer.Start(ctx, "petri.act", trace.WithAttributes(AttrSignal.String(fmt.Sprint(signal))))
+		defer span.End()
+
+		report, err := next(ctx)
+		if report != nil {
+			span.SetAttributes(
+				AttrCompleted.StringSlice(idStrings(report.Completed)),
+				AttrDeadLettered.StringSlice(idStrings(report.DeadLettered)),
+				AttrCascade.Int(report.Cascade),
+			)
+		}
+
+		fail(span, err)
+
+		return report, err
+	}
+}
+
+// Interceptor returns a graph interceptor starting a span for each handler
+// call. The priority level is recorded when the graph acts in a PetriQueue.
+func Interceptor[T any, V comparable](provider trace.TracerProvider) graph.Interceptor[T, V] {
+	tracer := tracerOf(provider)
+
+	return func(ctx context.Context, call graph.Call[T, V], next func(context.Context) error) error {
+		attrs := []attribute.KeyValue{
+			AttrStage.String(call.Stage.String()),
+			AttrGraphID.String(fmt.Sprint(call.GraphID)),
+			AttrNodeID.String(fmt.Sprint(call.NodeID)),
+			AttrSignal.String(fmt.Sprint(call.Signal)),
+		}
+
+		if level, ok := aggregate.PriorityFrom(ctx); ok {
+			attrs = append(attrs, AttrPriority.Int(level))
+		}
+
+		ctx, span := tracer.Start(ctx, "petri."+call.Stage.String(), trace.WithAttributes(attrs...))
+		defer span.End()
+
+		err := next(ctx)
+		fail(span, err)
+
+		return err
+	}
+}
+
+func tracerOf(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return provider.Tracer(instrumentation)
+}
+
+// fail marks the span as failed, an ignored signal is not a failure.
+func fail(span trace.Span, err error) {
+	if err == nil || errors.Is(err, graph.ErrSignalIgnored) {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+func idStrings[V comparable](ids []V) []string {
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, fmt.Sprint(id))
+	}
+
+	return result
+}
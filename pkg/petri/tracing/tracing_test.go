@@ -0,0 +1,131 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/uzh13/GuePetri/pkg/petri/aggregate"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/graph"
+	"github.com/uzh13/GuePetri/pkg/petri/primitives/priority"
+	"github.com/uzh13/GuePetri/pkg/petri/tracing"
+)
+
+type lifecycle struct{}
+
+func (lifecycle) HandleIn() error  { return nil }
+func (lifecycle) HandleOut() error { return nil }
+
+// place leads the token to next and refuses to take it in with err.
+type place struct {
+	next *graph.Transition[string, string]
+	err  error
+}
+
+func (p *place) HandleIn(*graph.Place[string, string]) error  { return p.err }
+func (p *place) HandleOut(*graph.Place[string, string]) error { return nil }
+
+func (p *place) ChooseTo(string) (*graph.Transition[string, string], error) {
+	return p.next, nil
+}
+
+type move struct {
+	to *graph.Place[string, string]
+}
+
+func (m *move) Handle(*graph.Place[string, string], string) (*graph.Place[string, string], error) {
+	return m.to, nil
+}
+
+// newQueue queues graph1, start -> go -> finish, on level 3.
+func newQueue(t *testing.T, finishErr error) (*aggregate.PetriQueue[string, string], *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	target := tracing.Instrument(aggregate.NewPetriQueue[string, string](priority.NewPriorityQueue[string, string](), "0"), provider)
+
+	finish := graph.NewPlace[string, string]("finish", &place{err: finishErr})
+	transition := graph.NewTransition[string, string]("go", &move{to: finish}).AddTo(finish)
+	start := graph.NewPlace[string, string]("start", &place{next: transition}).AddTransition(transition)
+
+	g := graph.NewPetri[string, string]("graph1", lifecycle{}).
+		SetStartPlace(start).
+		SetFinishPlace(finish)
+
+	err := target.AddGraph(3, g)
+	if err != nil {
+		t.Fatalf("add graph: %v", err)
+	}
+
+	exporter.Reset()
+
+	return target, exporter
+}
+
+func attrs(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	result := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		result[kv.Key] = kv.Value
+	}
+
+	return result
+}
+
+func TestInstrument(t *testing.T) {
+	target, exporter := newQueue(t, nil)
+
+	err := target.ActContext(context.Background(), "sig")
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+
+	assert.Equal(t, []string{
+		"petri.choose_to",
+		"petri.handle",
+		"petri.place_handle_out",
+		"petri.place_handle_in",
+		"petri.place_handle_out",
+		"petri.graph_handle_out",
+		"petri.act",
+	}, names)
+
+	root := spans[len(spans)-1]
+	assert.Equal(t, "sig", attrs(root)[tracing.AttrSignal].AsString())
+	assert.Equal(t, []string{"graph1"}, attrs(root)[tracing.AttrCompleted].AsStringSlice())
+
+	for _, span := range spans[:len(spans)-1] {
+		assert.Equal(t, root.SpanContext.SpanID(), span.Parent.SpanID(), span.Name)
+		assert.Equal(t, "graph1", attrs(span)[tracing.AttrGraphID].AsString(), span.Name)
+		assert.Equal(t, int64(3), attrs(span)[tracing.AttrPriority].AsInt64(), span.Name)
+	}
+
+	handle := attrs(spans[1])
+	assert.Equal(t, "go", handle[tracing.AttrNodeID].AsString())
+	assert.Equal(t, "handle", handle[tracing.AttrStage].AsString())
+}
+
+func TestInstrument_Error(t *testing.T) {
+	target, exporter := newQueue(t, errors.New("closed"))
+
+	err := target.Act("sig")
+	assert.Error(t, err)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, "petri.place_handle_in", spans[len(spans)-2].Name)
+	assert.Equal(t, codes.Error, spans[len(spans)-2].Status.Code)
+	assert.Equal(t, "petri.act", spans[len(spans)-1].Name)
+	assert.Equal(t, codes.Error, spans[len(spans)-1].Status.Code)
+}